@@ -0,0 +1,82 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// AveragePoolingLayer downsamples channel-major feature maps stored one per
+// row by averaging each poolHeight x poolWidth window.
+type AveragePoolingLayer struct {
+	channel    int
+	height     int
+	width      int
+	poolHeight int
+	poolWidth  int
+	stride     int
+	pad        int
+
+	batchSize int
+}
+
+func InitAveragePoolingLayer(channel, height, width, poolHeight, poolWidth, stride, pad int) *AveragePoolingLayer {
+	return &AveragePoolingLayer{
+		channel:    channel,
+		height:     height,
+		width:      width,
+		poolHeight: poolHeight,
+		poolWidth:  poolWidth,
+		stride:     stride,
+		pad:        pad,
+	}
+}
+
+func (a *AveragePoolingLayer) OutputShape() (channel, height, width int) {
+	channel = a.channel
+	height = convOutputSize(a.height, a.poolHeight, a.stride, a.pad)
+	width = convOutputSize(a.width, a.poolWidth, a.stride, a.pad)
+	return
+}
+
+func (a *AveragePoolingLayer) Forward(x *mat.Dense) *mat.Dense {
+	a.batchSize, _ = x.Dims()
+	_, oh, ow := a.OutputShape()
+	size := oh * ow
+	window := a.poolHeight * a.poolWidth
+
+	col := im2col(x, a.channel, a.height, a.width, a.poolHeight, a.poolWidth, a.stride, a.stride, a.pad, a.pad)
+	out := mat.NewDense(a.batchSize, a.channel*size, nil)
+
+	for i := 0; i < a.batchSize; i++ {
+		for p := 0; p < size; p++ {
+			for ch := 0; ch < a.channel; ch++ {
+				sum := 0.0
+				for k := 0; k < window; k++ {
+					sum = sum + col.At(i*size+p, ch*window+k)
+				}
+				out.Set(i, ch*size+p, sum/float64(window))
+			}
+		}
+	}
+
+	return out
+}
+
+func (a *AveragePoolingLayer) Backward(dout *mat.Dense) *mat.Dense {
+	_, oh, ow := a.OutputShape()
+	size := oh * ow
+	window := a.poolHeight * a.poolWidth
+
+	dcol := mat.NewDense(a.batchSize*size, a.channel*window, nil)
+	for i := 0; i < a.batchSize; i++ {
+		for p := 0; p < size; p++ {
+			for ch := 0; ch < a.channel; ch++ {
+				v := dout.At(i, ch*size+p) / float64(window)
+				for k := 0; k < window; k++ {
+					dcol.Set(i*size+p, ch*window+k, v)
+				}
+			}
+		}
+	}
+
+	return col2im(dcol, a.batchSize, a.channel, a.height, a.width, a.poolHeight, a.poolWidth, a.stride, a.stride, a.pad, a.pad)
+}
@@ -6,13 +6,25 @@ import (
 	"gonum.org/v1/gonum/mat"
 )
 
+// BatchNormLayer normalizes each feature with the statistics of the current
+// batch while training. It keeps running averages of the mean and variance,
+// updated with momentum, and normalizes with them at inference. A frozen
+// layer normalizes with the batch statistics without updating the running
+// averages.
 type BatchNormLayer struct {
-	gamma []float64
-	beta  []float64
-	diff  *mat.Dense
-	s2b   []float64
-	den   []float64
-	norm  *mat.Dense
+	gamma    []float64
+	beta     []float64
+	momentum float64
+	train    bool
+	freeze   bool
+
+	runningMean []float64
+	runningVar  []float64
+
+	diff *mat.Dense
+	s2b  []float64
+	den  []float64
+	norm *mat.Dense
 
 	dgamma []float64
 	dbeta  []float64
@@ -20,11 +32,28 @@ type BatchNormLayer struct {
 
 func InitBatchNormLayer(g, b []float64) NormalizationLayer {
 	return &BatchNormLayer{
-		gamma: g,
-		beta:  b,
+		gamma:    g,
+		beta:     b,
+		momentum: 0.9,
 	}
 }
 
+func (b *BatchNormLayer) SetTrainFlag(train bool) {
+	b.train = train
+}
+
+func (b *BatchNormLayer) SetFreezeFlag(freeze bool) {
+	b.freeze = freeze
+}
+
+func (b *BatchNormLayer) GetDGamma() []float64 {
+	return b.dgamma
+}
+
+func (b *BatchNormLayer) GetDBeta() []float64 {
+	return b.dbeta
+}
+
 // func (b *BatchNormLayer) Forward(x *mat.Dense) *mat.Dense {
 // 	return x
 // }
@@ -35,6 +64,19 @@ func InitBatchNormLayer(g, b []float64) NormalizationLayer {
 
 func (b *BatchNormLayer) Forward(x *mat.Dense) *mat.Dense {
 	r, c := x.Dims()
+
+	if b.runningMean == nil {
+		b.runningMean = make([]float64, c)
+		b.runningVar = make([]float64, c)
+		for i := 0; i < c; i++ {
+			b.runningVar[i] = 1.0
+		}
+	}
+
+	if !b.train {
+		return b.forwardWithRunningStats(x)
+	}
+
 	out := mat.NewDense(r, c, nil)
 	b.diff = mat.NewDense(r, c, nil)
 	mb := make([]float64, c)
@@ -65,6 +107,10 @@ func (b *BatchNormLayer) Forward(x *mat.Dense) *mat.Dense {
 	epsilon := math.Pow10(-7)
 	for i := 0; i < c; i++ {
 		b.s2b[i] = b.s2b[i] / float64(r)
+		if !b.freeze {
+			b.runningMean[i] = b.momentum*b.runningMean[i] + (1-b.momentum)*mb[i]
+			b.runningVar[i] = b.momentum*b.runningVar[i] + (1-b.momentum)*b.s2b[i]
+		}
 		b.den[i] = 1.0 / math.Sqrt(b.s2b[i]+epsilon)
 		b.s2b[i] = 1.0 / (b.s2b[i] + epsilon)
 	}
@@ -81,6 +127,21 @@ func (b *BatchNormLayer) Forward(x *mat.Dense) *mat.Dense {
 	return out
 }
 
+func (b *BatchNormLayer) forwardWithRunningStats(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+
+	epsilon := math.Pow10(-7)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			tmp := (x.At(i, j) - b.runningMean[j]) / math.Sqrt(b.runningVar[j]+epsilon)
+			out.Set(i, j, tmp*b.gamma[j]+b.beta[j])
+		}
+	}
+
+	return out
+}
+
 func (b *BatchNormLayer) Backward(dout *mat.Dense) *mat.Dense {
 	r, c := dout.Dims()
 	dx := mat.NewDense(r, c, nil)
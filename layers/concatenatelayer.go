@@ -0,0 +1,47 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// ConcatenateLayer joins the outputs of several branches along the feature
+// axis, in the order they are given, and splits the gradient back into one
+// part per branch.
+type ConcatenateLayer struct {
+	sizes []int
+}
+
+func InitConcatenateLayer() *ConcatenateLayer {
+	return &ConcatenateLayer{}
+}
+
+func (c *ConcatenateLayer) Forward(xs ...*mat.Dense) *mat.Dense {
+	r, _ := xs[0].Dims()
+	c.sizes = make([]int, len(xs))
+	total := 0
+	for i, x := range xs {
+		_, c.sizes[i] = x.Dims()
+		total = total + c.sizes[i]
+	}
+
+	out := mat.NewDense(r, total, nil)
+	offset := 0
+	for i, x := range xs {
+		out.Slice(0, r, offset, offset+c.sizes[i]).(*mat.Dense).Copy(x)
+		offset = offset + c.sizes[i]
+	}
+
+	return out
+}
+
+func (c *ConcatenateLayer) Backward(dout *mat.Dense) []*mat.Dense {
+	r, _ := dout.Dims()
+	dxs := make([]*mat.Dense, len(c.sizes))
+	offset := 0
+	for i, size := range c.sizes {
+		dxs[i] = mat.DenseCopyOf(dout.Slice(0, r, offset, offset+size))
+		offset = offset + size
+	}
+
+	return dxs
+}
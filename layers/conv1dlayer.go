@@ -0,0 +1,36 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Conv1DLayer convolves sequences stored one per row in channel-major order
+// (channel, length) along their length. W holds one filter per column, laid
+// out as (channel*filterSize, filterNum), and B is (1, filterNum). The
+// output rows are channel-major sequences of filterNum channels.
+type Conv1DLayer struct {
+	Conv2DLayer
+}
+
+func InitConv1DLayer(w, b *mat.Dense, channel, length, filterSize, stride, pad int) *Conv1DLayer {
+	return &Conv1DLayer{
+		Conv2DLayer: Conv2DLayer{
+			W:            w,
+			B:            b,
+			channel:      channel,
+			height:       1,
+			width:        length,
+			filterHeight: 1,
+			filterWidth:  filterSize,
+			strideHeight: 1,
+			strideWidth:  stride,
+			padHeight:    0,
+			padWidth:     pad,
+		},
+	}
+}
+
+func (c *Conv1DLayer) OutputShape() (channel, length int) {
+	channel, _, length = c.Conv2DLayer.OutputShape()
+	return
+}
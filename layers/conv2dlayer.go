@@ -0,0 +1,108 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Conv2DLayer convolves images stored one per row in channel-major order
+// (channel, height, width). W holds one filter per column, laid out as
+// (channel*filterHeight*filterWidth, filterNum), and B is (1, filterNum).
+// The output rows are channel-major feature maps of filterNum channels.
+type Conv2DLayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	channel      int
+	height       int
+	width        int
+	filterHeight int
+	filterWidth  int
+	strideHeight int
+	strideWidth  int
+	padHeight    int
+	padWidth     int
+
+	batchSize int
+	col       *mat.Dense
+}
+
+func InitConv2DLayer(w, b *mat.Dense, channel, height, width, filterHeight, filterWidth, stride, pad int) *Conv2DLayer {
+	return &Conv2DLayer{
+		W:            w,
+		B:            b,
+		channel:      channel,
+		height:       height,
+		width:        width,
+		filterHeight: filterHeight,
+		filterWidth:  filterWidth,
+		strideHeight: stride,
+		strideWidth:  stride,
+		padHeight:    pad,
+		padWidth:     pad,
+	}
+}
+
+func (c *Conv2DLayer) OutputShape() (channel, height, width int) {
+	_, channel = c.W.Dims()
+	height = convOutputSize(c.height, c.filterHeight, c.strideHeight, c.padHeight)
+	width = convOutputSize(c.width, c.filterWidth, c.strideWidth, c.padWidth)
+	return
+}
+
+func (c *Conv2DLayer) GetDB() *mat.Dense {
+	return c.DB
+}
+
+func (c *Conv2DLayer) GetDW() *mat.Dense {
+	return c.DW
+}
+
+func (c *Conv2DLayer) Forward(x *mat.Dense) *mat.Dense {
+	c.batchSize, _ = x.Dims()
+	fn, oh, ow := c.OutputShape()
+	size := oh * ow
+
+	c.col = im2col(x, c.channel, c.height, c.width, c.filterHeight, c.filterWidth, c.strideHeight, c.strideWidth, c.padHeight, c.padWidth)
+	r, _ := c.col.Dims()
+	tmp := mat.NewDense(r, fn, nil)
+	tmp.Mul(c.col, c.W)
+
+	out := mat.NewDense(c.batchSize, fn*size, nil)
+	for i := 0; i < c.batchSize; i++ {
+		for p := 0; p < size; p++ {
+			for f := 0; f < fn; f++ {
+				out.Set(i, f*size+p, tmp.At(i*size+p, f)+c.B.At(0, f))
+			}
+		}
+	}
+
+	return out
+}
+
+func (c *Conv2DLayer) Backward(dout *mat.Dense) *mat.Dense {
+	fn, oh, ow := c.OutputShape()
+	size := oh * ow
+
+	tmp := mat.NewDense(c.batchSize*size, fn, nil)
+	c.DB = mat.NewDense(1, fn, nil)
+	for i := 0; i < c.batchSize; i++ {
+		for p := 0; p < size; p++ {
+			for f := 0; f < fn; f++ {
+				v := dout.At(i, f*size+p)
+				tmp.Set(i*size+p, f, v)
+				c.DB.Set(0, f, c.DB.At(0, f)+v)
+			}
+		}
+	}
+
+	rw, cw := c.W.Dims()
+	c.DW = mat.NewDense(rw, cw, nil)
+	c.DW.Mul(c.col.T(), tmp)
+
+	dcol := mat.NewDense(c.batchSize*size, rw, nil)
+	dcol.Mul(tmp, c.W.T())
+
+	return col2im(dcol, c.batchSize, c.channel, c.height, c.width, c.filterHeight, c.filterWidth, c.strideHeight, c.strideWidth, c.padHeight, c.padWidth)
+}
@@ -0,0 +1,136 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Conv2DTransposeLayer is the transpose of Conv2DLayer: it maps channel-major
+// images of channel x height x width, stored one per row, to larger ones of
+// filterNum channels, as a convolution of the same filter, stride and pad
+// maps the larger images back to the smaller. W is
+// (channel, filterNum*filterHeight*filterWidth) and B is (1, filterNum).
+type Conv2DTransposeLayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	channel      int
+	height       int
+	width        int
+	filterHeight int
+	filterWidth  int
+	stride       int
+	pad          int
+
+	batchSize int
+	x         *mat.Dense
+}
+
+func InitConv2DTransposeLayer(w, b *mat.Dense, channel, height, width, filterHeight, filterWidth, stride, pad int) *Conv2DTransposeLayer {
+	return &Conv2DTransposeLayer{
+		W:            w,
+		B:            b,
+		channel:      channel,
+		height:       height,
+		width:        width,
+		filterHeight: filterHeight,
+		filterWidth:  filterWidth,
+		stride:       stride,
+		pad:          pad,
+	}
+}
+
+func (c *Conv2DTransposeLayer) OutputShape() (channel, height, width int) {
+	_, channel = c.B.Dims()
+	height = (c.height-1)*c.stride - 2*c.pad + c.filterHeight
+	width = (c.width-1)*c.stride - 2*c.pad + c.filterWidth
+	return
+}
+
+func (c *Conv2DTransposeLayer) GetDB() *mat.Dense {
+	return c.DB
+}
+
+func (c *Conv2DTransposeLayer) GetDW() *mat.Dense {
+	return c.DW
+}
+
+// channelsToRows rearranges channel-major rows of channel x size values into
+// one row of channel values per sample and position.
+func channelsToRows(x *mat.Dense, channel, size int) *mat.Dense {
+	n, _ := x.Dims()
+	out := mat.NewDense(n*size, channel, nil)
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < channel; ch++ {
+			for p := 0; p < size; p++ {
+				out.Set(i*size+p, ch, x.At(i, ch*size+p))
+			}
+		}
+	}
+	return out
+}
+
+// rowsToChannels is the inverse of channelsToRows.
+func rowsToChannels(x *mat.Dense, size int) *mat.Dense {
+	r, channel := x.Dims()
+	n := r / size
+	out := mat.NewDense(n, channel*size, nil)
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < channel; ch++ {
+			for p := 0; p < size; p++ {
+				out.Set(i, ch*size+p, x.At(i*size+p, ch))
+			}
+		}
+	}
+	return out
+}
+
+func (c *Conv2DTransposeLayer) Forward(x *mat.Dense) *mat.Dense {
+	c.batchSize, _ = x.Dims()
+	fn, oh, ow := c.OutputShape()
+
+	c.x = channelsToRows(x, c.channel, c.height*c.width)
+	r, _ := c.x.Dims()
+	_, cw := c.W.Dims()
+	col := mat.NewDense(r, cw, nil)
+	col.Mul(c.x, c.W)
+
+	out := col2im(col, c.batchSize, fn, oh, ow, c.filterHeight, c.filterWidth, c.stride, c.stride, c.pad, c.pad)
+	size := oh * ow
+	for i := 0; i < c.batchSize; i++ {
+		for f := 0; f < fn; f++ {
+			for p := 0; p < size; p++ {
+				out.Set(i, f*size+p, out.At(i, f*size+p)+c.B.At(0, f))
+			}
+		}
+	}
+
+	return out
+}
+
+func (c *Conv2DTransposeLayer) Backward(dout *mat.Dense) *mat.Dense {
+	fn, oh, ow := c.OutputShape()
+	size := oh * ow
+
+	c.DB = mat.NewDense(1, fn, nil)
+	for i := 0; i < c.batchSize; i++ {
+		for f := 0; f < fn; f++ {
+			for p := 0; p < size; p++ {
+				c.DB.Set(0, f, c.DB.At(0, f)+dout.At(i, f*size+p))
+			}
+		}
+	}
+
+	dcol := im2col(dout, fn, oh, ow, c.filterHeight, c.filterWidth, c.stride, c.stride, c.pad, c.pad)
+
+	rw, cw := c.W.Dims()
+	c.DW = mat.NewDense(rw, cw, nil)
+	c.DW.Mul(c.x.T(), dcol)
+
+	r, _ := dcol.Dims()
+	dx := mat.NewDense(r, rw, nil)
+	dx.Mul(dcol, c.W.T())
+
+	return rowsToChannels(dx, c.height*c.width)
+}
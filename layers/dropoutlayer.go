@@ -0,0 +1,81 @@
+package layers
+
+import (
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// DropoutLayer zeroes each unit with probability ratio while training and
+// scales the kept units by 1/(1-ratio), so that it is the identity at
+// inference. A frozen layer keeps the mask it last drew for as long as the
+// batch has the same shape.
+type DropoutLayer struct {
+	ratio  float64
+	train  bool
+	freeze bool
+	mask   [][]bool
+}
+
+func InitDropoutLayer(ratio float64) *DropoutLayer {
+	return &DropoutLayer{
+		ratio: ratio,
+	}
+}
+
+func (d *DropoutLayer) SetTrainFlag(train bool) {
+	d.train = train
+}
+
+func (d *DropoutLayer) SetFreezeFlag(freeze bool) {
+	d.freeze = freeze
+}
+
+func (d *DropoutLayer) Forward(x *mat.Dense) *mat.Dense {
+	if !d.train {
+		return x
+	}
+
+	rows, cols := x.Dims()
+	if !d.freeze || len(d.mask) != rows || len(d.mask[0]) != cols {
+		d.mask = make([][]bool, rows)
+		for i := 0; i < rows; i++ {
+			d.mask[i] = make([]bool, cols)
+			for j := 0; j < cols; j++ {
+				d.mask[i][j] = rand.Float64() >= d.ratio
+			}
+		}
+	}
+
+	out := mat.NewDense(rows, cols, nil)
+	scale := 1.0 / (1.0 - d.ratio)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if d.mask[i][j] {
+				out.Set(i, j, x.At(i, j)*scale)
+			}
+		}
+	}
+
+	return out
+}
+
+func (d *DropoutLayer) Backward(dout *mat.Dense) *mat.Dense {
+	if !d.train {
+		return dout
+	}
+
+	rows, cols := dout.Dims()
+	dx := mat.NewDense(rows, cols, nil)
+	scale := 1.0 / (1.0 - d.ratio)
+
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if d.mask[i][j] {
+				dx.Set(i, j, dout.At(i, j)*scale)
+			}
+		}
+	}
+
+	return dx
+}
@@ -0,0 +1,54 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// EluLayer passes positive inputs through and maps the others to
+// alpha*(exp(x)-1).
+type EluLayer struct {
+	alpha float64
+	x     *mat.Dense
+	out   *mat.Dense
+}
+
+func InitEluLayer(alpha float64) ActivationLayer {
+	return &EluLayer{
+		alpha: alpha,
+	}
+}
+
+func (e *EluLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := x.At(i, j)
+			if v > 0 {
+				out.Set(i, j, v)
+			} else {
+				out.Set(i, j, e.alpha*(math.Exp(v)-1))
+			}
+		}
+	}
+	e.x = mat.DenseCopyOf(x)
+	e.out = mat.DenseCopyOf(out)
+	return out
+}
+
+func (e *EluLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			if e.x.At(i, j) > 0 {
+				dx.Set(i, j, dout.At(i, j))
+			} else {
+				dx.Set(i, j, dout.At(i, j)*(e.out.At(i, j)+e.alpha))
+			}
+		}
+	}
+	return dx
+}
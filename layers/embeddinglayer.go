@@ -0,0 +1,80 @@
+package layers
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// EmbeddingLayer maps integer ids to rows of the table W, which is
+// (vocabularySize, embeddingSize). Every column of the input holds one id,
+// so a row of sequenceLength ids becomes a row of sequenceLength
+// embeddings laid out one after another. It has no bias. Forward panics if
+// an id is not an integer in [0, vocabularySize).
+type EmbeddingLayer struct {
+	W   *mat.Dense
+	DW  *mat.Dense
+	ids [][]int
+}
+
+func InitEmbeddingLayer(w *mat.Dense) *EmbeddingLayer {
+	return &EmbeddingLayer{
+		W: w,
+	}
+}
+
+func (e *EmbeddingLayer) GetDB() *mat.Dense {
+	return nil
+}
+
+func (e *EmbeddingLayer) GetDW() *mat.Dense {
+	return e.DW
+}
+
+func (e *EmbeddingLayer) Forward(x *mat.Dense) *mat.Dense {
+	batchSize, length := x.Dims()
+	vocabularySize, size := e.W.Dims()
+
+	out := mat.NewDense(batchSize, length*size, nil)
+	e.ids = make([][]int, batchSize)
+	for i := 0; i < batchSize; i++ {
+		e.ids[i] = make([]int, length)
+		for t := 0; t < length; t++ {
+			v := x.At(i, t)
+			id := int(v)
+			if float64(id) != v || id < 0 || id >= vocabularySize {
+				panic(fmt.Sprintf("layers: id %g at (%d, %d) is not an integer in [0, %d)", v, i, t, vocabularySize))
+			}
+			e.ids[i][t] = id
+			for j := 0; j < size; j++ {
+				out.Set(i, t*size+j, e.W.At(id, j))
+			}
+		}
+	}
+
+	return out
+}
+
+// Backward adds dout onto the rows of DW whose ids were looked up, leaving
+// the others 0. The ids have no gradient, so the returned matrix is 0.
+func (e *EmbeddingLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := e.W.Dims()
+	e.DW = mat.NewDense(r, c, nil)
+
+	batchSize := len(e.ids)
+	length := 0
+	if batchSize > 0 {
+		length = len(e.ids[0])
+	}
+
+	for i := 0; i < batchSize; i++ {
+		for t := 0; t < length; t++ {
+			id := e.ids[i][t]
+			for j := 0; j < c; j++ {
+				e.DW.Set(id, j, e.DW.At(id, j)+dout.At(i, t*c+j))
+			}
+		}
+	}
+
+	return mat.NewDense(batchSize, length, nil)
+}
@@ -0,0 +1,38 @@
+package layers
+
+import (
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// FlattenLayer reinterprets samples of inputShape as vectors, for example
+// to pass the feature maps of a convolution to an AffineLayer.
+type FlattenLayer struct {
+	inputShape Shape
+}
+
+func InitFlattenLayer(inputShape Shape) *FlattenLayer {
+	return &FlattenLayer{
+		inputShape: inputShape,
+	}
+}
+
+func (f *FlattenLayer) InputShape() Shape {
+	return f.inputShape
+}
+
+func (f *FlattenLayer) OutputShape() Shape {
+	return Shape{f.inputShape.Size()}
+}
+
+func (f *FlattenLayer) Forward(x *mat.Dense) *mat.Dense {
+	if _, c := x.Dims(); c != f.inputShape.Size() {
+		panic(fmt.Sprintf("layers: input of %d values does not match shape %v", c, f.inputShape))
+	}
+	return x
+}
+
+func (f *FlattenLayer) Backward(dout *mat.Dense) *mat.Dense {
+	return dout
+}
@@ -0,0 +1,43 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// GeLuLayer computes x*Phi(x), where Phi is the standard normal CDF.
+type GeLuLayer struct {
+	x *mat.Dense
+}
+
+func InitGeLuLayer() ActivationLayer {
+	return &GeLuLayer{}
+}
+
+func (g *GeLuLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := x.At(i, j)
+			out.Set(i, j, v*0.5*(1+math.Erf(v/math.Sqrt2)))
+		}
+	}
+	g.x = mat.DenseCopyOf(x)
+	return out
+}
+
+func (g *GeLuLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := g.x.At(i, j)
+			cdf := 0.5 * (1 + math.Erf(v/math.Sqrt2))
+			pdf := math.Exp(-0.5*v*v) / math.Sqrt(2*math.Pi)
+			dx.Set(i, j, dout.At(i, j)*(cdf+v*pdf))
+		}
+	}
+	return dx
+}
@@ -0,0 +1,60 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// GlobalAveragePoolingLayer averages every channel of channel-major feature
+// maps stored one per row, whether images or sequences, down to a single
+// value per channel.
+type GlobalAveragePoolingLayer struct {
+	channel int
+	size    int
+}
+
+func InitGlobalAveragePooling1DLayer(channel, length int) *GlobalAveragePoolingLayer {
+	return &GlobalAveragePoolingLayer{
+		channel: channel,
+		size:    length,
+	}
+}
+
+func InitGlobalAveragePooling2DLayer(channel, height, width int) *GlobalAveragePoolingLayer {
+	return &GlobalAveragePoolingLayer{
+		channel: channel,
+		size:    height * width,
+	}
+}
+
+func (g *GlobalAveragePoolingLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, _ := x.Dims()
+	out := mat.NewDense(r, g.channel, nil)
+
+	for i := 0; i < r; i++ {
+		for ch := 0; ch < g.channel; ch++ {
+			sum := 0.0
+			for p := 0; p < g.size; p++ {
+				sum = sum + x.At(i, ch*g.size+p)
+			}
+			out.Set(i, ch, sum/float64(g.size))
+		}
+	}
+
+	return out
+}
+
+func (g *GlobalAveragePoolingLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, _ := dout.Dims()
+	dx := mat.NewDense(r, g.channel*g.size, nil)
+
+	for i := 0; i < r; i++ {
+		for ch := 0; ch < g.channel; ch++ {
+			v := dout.At(i, ch) / float64(g.size)
+			for p := 0; p < g.size; p++ {
+				dx.Set(i, ch*g.size+p, v)
+			}
+		}
+	}
+
+	return dx
+}
@@ -0,0 +1,484 @@
+package layers
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// gradientParam is a parameter the loss is differentiated with respect to,
+// together with the gradient Backward leaves for it.
+type gradientParam struct {
+	name  string
+	value *mat.Dense
+	grad  func() *mat.Dense
+}
+
+// gradientCase builds a layer, an input batch and the parameters whose
+// gradients Backward is expected to compute.
+type gradientCase struct {
+	name  string
+	setup func(rng *rand.Rand) (l ActivationLayer, x *mat.Dense, params []gradientParam)
+}
+
+var gradientCases = []gradientCase{
+	{"Conv2DLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 2*3*3, 3, 0.5)
+		b := randomDense(rng, 1, 3, 0.5)
+		l := InitConv2DLayer(w, b, 2, 4, 5, 3, 3, 1, 1)
+		return l, randomDense(rng, 2, 2*4*5, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"Conv2DLayer/stride", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 2*2*3, 3, 0.5)
+		b := randomDense(rng, 1, 3, 0.5)
+		l := InitConv2DLayer(w, b, 2, 5, 6, 2, 3, 2, 0)
+		return l, randomDense(rng, 2, 2*5*6, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"MaxPoolingLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitMaxPoolingLayer(2, 4, 4, 2, 2, 2, 0), randomDense(rng, 2, 2*4*4, 1.0), nil
+	}},
+	{"MaxPoolingLayer/pad", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitMaxPoolingLayer(2, 5, 4, 3, 2, 2, 1), randomDense(rng, 2, 2*5*4, 1.0), nil
+	}},
+	{"AveragePoolingLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitAveragePoolingLayer(2, 5, 4, 3, 2, 2, 1), randomDense(rng, 2, 2*5*4, 1.0), nil
+	}},
+	{"DropoutLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		l := InitDropoutLayer(0.5)
+		l.SetTrainFlag(true)
+		l.SetFreezeFlag(true)
+		return l, randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"BatchNormLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		g, b := randomDense(rng, 1, 4, 1.0), randomDense(rng, 1, 4, 1.0)
+		l := InitBatchNormLayer(g.RawRowView(0), b.RawRowView(0))
+		l.(TrainFlagSetter).SetTrainFlag(true)
+		l.(FreezeFlagSetter).SetFreezeFlag(true)
+		return l, randomDense(rng, 5, 4, 1.0), normalizationParams(l, g, b)
+	}},
+	{"LayerNormLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		g, b := randomDense(rng, 1, 5, 1.0), randomDense(rng, 1, 5, 1.0)
+		l := InitLayerNormLayer(g.RawRowView(0), b.RawRowView(0))
+		return l, randomDense(rng, 3, 5, 1.0), normalizationParams(l, g, b)
+	}},
+	{"TanhLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitTanhLayer(), randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"LeakyReLuLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitLeakyReLuLayer(0.1), randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"EluLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitEluLayer(0.5), randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"GeLuLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitGeLuLayer(), randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"SwishLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitSwishLayer(), randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"SoftplusLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitSoftplusLayer(), randomDense(rng, 3, 4, 1.0), nil
+	}},
+	{"RNNLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 3+5, 5, 0.5)
+		b := randomDense(rng, 1, 5, 0.5)
+		l := InitRNNLayer(w, b, 4, false)
+		return l, randomDense(rng, 2, 4*3, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"RNNLayer/sequences", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 3+5, 5, 0.5)
+		b := randomDense(rng, 1, 5, 0.5)
+		l := InitRNNLayer(w, b, 4, true)
+		return l, randomDense(rng, 2, 4*3, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"LSTMLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 3+5, 4*5, 0.5)
+		b := randomDense(rng, 1, 4*5, 0.5)
+		l := InitLSTMLayer(w, b, 4, false)
+		return l, randomDense(rng, 2, 4*3, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"LSTMLayer/sequences", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 3+5, 4*5, 0.5)
+		b := randomDense(rng, 1, 4*5, 0.5)
+		l := InitLSTMLayer(w, b, 4, true)
+		return l, randomDense(rng, 2, 4*3, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"GRULayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 3+5, 3*5, 0.5)
+		b := randomDense(rng, 1, 3*5, 0.5)
+		l := InitGRULayer(w, b, 4, false)
+		return l, randomDense(rng, 2, 4*3, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"GRULayer/sequences", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 3+5, 3*5, 0.5)
+		b := randomDense(rng, 1, 3*5, 0.5)
+		l := InitGRULayer(w, b, 4, true)
+		return l, randomDense(rng, 2, 4*3, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"MultiHeadAttentionLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 6, 4*6, 0.5)
+		b := randomDense(rng, 1, 4*6, 0.5)
+		l, err := InitMultiHeadAttentionLayer(w, b, 4, 2, false)
+		if err != nil {
+			panic(err)
+		}
+		return l, randomDense(rng, 2*4, 6, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"MultiHeadAttentionLayer/causal", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 6, 4*6, 0.5)
+		b := randomDense(rng, 1, 4*6, 0.5)
+		l, err := InitMultiHeadAttentionLayer(w, b, 4, 2, true)
+		if err != nil {
+			panic(err)
+		}
+		return l, randomDense(rng, 2*4, 6, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"Conv1DLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 2*3, 4, 0.5)
+		b := randomDense(rng, 1, 4, 0.5)
+		l := InitConv1DLayer(w, b, 2, 7, 3, 2, 1)
+		return l, randomDense(rng, 2, 2*7, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"GlobalAveragePooling1DLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitGlobalAveragePooling1DLayer(3, 5), randomDense(rng, 2, 3*5, 1.0), nil
+	}},
+	{"GlobalAveragePooling2DLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitGlobalAveragePooling2DLayer(3, 2, 4), randomDense(rng, 2, 3*2*4, 1.0), nil
+	}},
+	{"Conv2DTransposeLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 2, 3*3*3, 0.5)
+		b := randomDense(rng, 1, 3, 0.5)
+		l := InitConv2DTransposeLayer(w, b, 2, 3, 4, 3, 3, 1, 1)
+		return l, randomDense(rng, 2, 2*3*4, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"Conv2DTransposeLayer/stride", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 2, 3*3*2, 0.5)
+		b := randomDense(rng, 1, 3, 0.5)
+		l := InitConv2DTransposeLayer(w, b, 2, 3, 4, 3, 2, 2, 1)
+		return l, randomDense(rng, 2, 2*3*4, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"UpsamplingLayer/nearest", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitUpsamplingLayer(2, 3, 2, 2, UpsamplingAlgorismNearest), randomDense(rng, 2, 2*3*2, 1.0), nil
+	}},
+	{"UpsamplingLayer/bilinear", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		return InitUpsamplingLayer(2, 3, 2, 3, UpsamplingAlgorismBilinear), randomDense(rng, 2, 2*3*2, 1.0), nil
+	}},
+	{"GroupNormLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		g, b := randomDense(rng, 1, 4, 1.0), randomDense(rng, 1, 4, 1.0)
+		l, err := InitGroupNormLayer(g.RawRowView(0), b.RawRowView(0), 2, 3)
+		if err != nil {
+			panic(err)
+		}
+		return l, randomDense(rng, 2, 4*3, 1.0), normalizationParams(l, g, b)
+	}},
+	{"InstanceNormLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		g, b := randomDense(rng, 1, 3, 1.0), randomDense(rng, 1, 3, 1.0)
+		l := InitInstanceNormLayer(g.RawRowView(0), b.RawRowView(0), 4)
+		return l, randomDense(rng, 2, 3*4, 1.0), normalizationParams(l, g, b)
+	}},
+	{"PReLuLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		alpha := randomDense(rng, 1, 3, 0.5)
+		l := InitPReLuLayer(alpha.RawRowView(0), 2)
+		dalpha := func() *mat.Dense { return mat.NewDense(1, 3, l.GetDAlpha()) }
+		return l, randomDense(rng, 2, 3*2, 1.0), []gradientParam{{"alpha", alpha, dalpha}}
+	}},
+	{"MaxoutLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 4, 3*2, 0.5)
+		b := randomDense(rng, 1, 3*2, 0.5)
+		l := InitMaxoutLayer(w, b, 3)
+		return l, randomDense(rng, 2, 4, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+	{"WeightNormLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		v := randomDense(rng, 4, 3, 0.5)
+		b := randomDense(rng, 1, 3, 0.5)
+		g := randomDense(rng, 1, 3, 1.0)
+		l := InitWeightNormLayer(v, b, g.RawRowView(0))
+		dg := func() *mat.Dense { return mat.NewDense(1, 3, l.GetDG()) }
+		return l, randomDense(rng, 2, 4, 1.0), []gradientParam{{"V", v, l.GetDW}, {"B", b, l.GetDB}, {"g", g, dg}}
+	}},
+	{"SpectralNormLayer", func(rng *rand.Rand) (ActivationLayer, *mat.Dense, []gradientParam) {
+		w := randomDense(rng, 4, 3, 0.5)
+		b := randomDense(rng, 1, 3, 0.5)
+		l := InitSpectralNormLayer(w, b)
+		l.SetTrainFlag(true)
+		l.SetFreezeFlag(true)
+		return l, randomDense(rng, 2, 4, 1.0), []gradientParam{{"W", w, l.GetDW}, {"B", b, l.GetDB}}
+	}},
+}
+
+func TestLayerBackward(t *testing.T) {
+	for _, c := range gradientCases {
+		t.Run(c.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			l, x, params := c.setup(rng)
+			checkGradient(t, rng, l, x, params)
+		})
+	}
+}
+
+// normalizationParams returns gamma and beta, given as 1 x n matrices
+// sharing memory with the slices l was built with.
+func normalizationParams(l NormalizationLayer, g, b *mat.Dense) []gradientParam {
+	return []gradientParam{
+		{"gamma", g, func() *mat.Dense { return mat.NewDense(1, len(l.GetDGamma()), l.GetDGamma()) }},
+		{"beta", b, func() *mat.Dense { return mat.NewDense(1, len(l.GetDBeta()), l.GetDBeta()) }},
+	}
+}
+
+func randomDense(rng *rand.Rand, r, c int, std float64) *mat.Dense {
+	d := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			d.Set(i, j, rng.NormFloat64()*std)
+		}
+	}
+	return d
+}
+
+// numericalGradient returns the central difference of f with respect to
+// every element of x.
+func numericalGradient(f func() float64, x *mat.Dense) *mat.Dense {
+	h := math.Pow10(-5)
+	r, c := x.Dims()
+	grad := mat.NewDense(r, c, nil)
+
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			tmpval := x.At(i, j)
+			x.Set(i, j, tmpval-h)
+			fx0 := f()
+			x.Set(i, j, tmpval+h)
+			fx1 := f()
+
+			grad.Set(i, j, (fx1-fx0)/(2*h))
+			x.Set(i, j, tmpval)
+		}
+	}
+
+	return grad
+}
+
+// checkGradient compares the gradients Backward computes with respect to x
+// and params with numerical ones, for the loss sum(Forward(x) * dout) of a
+// random dout.
+func checkGradient(t *testing.T, rng *rand.Rand, l ActivationLayer, x *mat.Dense, params []gradientParam) {
+	t.Helper()
+
+	r, c := l.Forward(x).Dims()
+	dout := randomDense(rng, r, c, 1.0)
+	loss := func() float64 {
+		out := l.Forward(x)
+		out.MulElem(out, dout)
+		return mat.Sum(out)
+	}
+
+	l.Forward(x)
+	dx := l.Backward(dout)
+	params = append([]gradientParam{{"x", x, func() *mat.Dense { return dx }}}, params...)
+
+	for _, p := range params {
+		var diff mat.Dense
+		diff.Sub(p.grad(), numericalGradient(loss, p.value))
+		if d := mat.Norm(&diff, math.Inf(1)); d > 1e-6 {
+			t.Errorf("gradient with respect to %s differs from the numerical one by %g", p.name, d)
+		}
+	}
+}
+
+func TestConv2DLayerForward(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	channel, height, width := 2, 4, 5
+	filterNum, filterHeight, filterWidth, stride, pad := 3, 3, 2, 2, 1
+
+	w := randomDense(rng, channel*filterHeight*filterWidth, filterNum, 1.0)
+	b := randomDense(rng, 1, filterNum, 1.0)
+	x := randomDense(rng, 2, channel*height*width, 1.0)
+	l := InitConv2DLayer(w, b, channel, height, width, filterHeight, filterWidth, stride, pad)
+	out := l.Forward(x)
+
+	_, oh, ow := l.OutputShape()
+	for n := 0; n < 2; n++ {
+		for f := 0; f < filterNum; f++ {
+			for i := 0; i < oh; i++ {
+				for j := 0; j < ow; j++ {
+					want := b.At(0, f)
+					for ch := 0; ch < channel; ch++ {
+						for fi := 0; fi < filterHeight; fi++ {
+							for fj := 0; fj < filterWidth; fj++ {
+								y, z := i*stride+fi-pad, j*stride+fj-pad
+								if y < 0 || y >= height || z < 0 || z >= width {
+									continue
+								}
+								row := (ch*filterHeight+fi)*filterWidth + fj
+								want += x.At(n, (ch*height+y)*width+z) * w.At(row, f)
+							}
+						}
+					}
+					if got := out.At(n, (f*oh+i)*ow+j); math.Abs(got-want) > 1e-9 {
+						t.Fatalf("output (%d, %d, %d, %d) = %g, want %g", n, f, i, j, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMaxPoolingLayerIgnoresPadding(t *testing.T) {
+	x := mat.NewDense(1, 4, []float64{-4, -3, -2, -1})
+	l := InitMaxPoolingLayer(1, 2, 2, 2, 2, 2, 1)
+	out := l.Forward(x)
+
+	want := []float64{-4, -3, -2, -1}
+	for j, v := range want {
+		if got := out.At(0, j); got != v {
+			t.Errorf("output %d = %g, want %g", j, got, v)
+		}
+	}
+}
+
+func TestBatchNormLayerRunningStats(t *testing.T) {
+	x := mat.NewDense(2, 1, []float64{1, 3})
+	l := InitBatchNormLayer([]float64{1}, []float64{0})
+
+	l.(TrainFlagSetter).SetTrainFlag(true)
+	l.Forward(x)
+	l.(FreezeFlagSetter).SetFreezeFlag(true)
+	l.Forward(mat.NewDense(2, 1, []float64{10, 20}))
+
+	// One update with momentum 0.9 from mean 0 and variance 1 towards the
+	// batch mean 2 and variance 1; the frozen pass must not move them.
+	l.(TrainFlagSetter).SetTrainFlag(false)
+	out := l.Forward(mat.NewDense(1, 1, []float64{2.2}))
+	if got, want := out.At(0, 0), (2.2-0.2)/math.Sqrt(1+1e-7); math.Abs(got-want) > 1e-12 {
+		t.Errorf("inference output = %g, want %g", got, want)
+	}
+}
+
+func TestInitWeightedSoftmaxWithLossLayer(t *testing.T) {
+	cases := []struct {
+		name           string
+		classNum       int
+		labelSmoothing float64
+		classWeights   []float64
+		valid          bool
+	}{
+		{"unweighted", 3, 0.1, nil, true},
+		{"weighted", 3, 0, []float64{1, 2, 0.5}, true},
+		{"no classes", 0, 0, nil, false},
+		{"smoothing of 1", 3, 1, nil, false},
+		{"negative smoothing", 3, -0.1, nil, false},
+		{"too few weights", 3, 0, []float64{1, 2}, false},
+		{"negative weight", 3, 0, []float64{1, -2, 1}, false},
+	}
+
+	for _, c := range cases {
+		_, err := InitWeightedSoftmaxWithLossLayer(c.classNum, c.labelSmoothing, c.classWeights)
+		if (err == nil) != c.valid {
+			t.Errorf("%s: err = %v", c.name, err)
+		}
+	}
+}
+
+func TestWeightedSoftmaxWithLossLayerBackward(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	x := randomDense(rng, 4, 3, 1.0)
+	tt := mat.NewDense(4, 3, []float64{1, 0, 0, 0, 1, 0, 0, 0, 1, 0, 1, 0})
+
+	l, err := InitWeightedSoftmaxWithLossLayer(3, 0.1, []float64{1, 2, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Forward(x, tt)
+	dx := l.Backward(1.0)
+
+	var diff mat.Dense
+	diff.Sub(dx, numericalGradient(func() float64 { return l.Forward(x, tt) }, x))
+	if d := mat.Norm(&diff, math.Inf(1)); d > 1e-6 {
+		t.Errorf("gradient differs from the numerical one by %g", d)
+	}
+}
+
+func TestEmbeddingLayerBackward(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	w := randomDense(rng, 5, 3, 1.0)
+	x := mat.NewDense(2, 3, []float64{0, 4, 0, 2, 2, 1})
+	l := InitEmbeddingLayer(w)
+
+	out := l.Forward(x)
+	r, c := out.Dims()
+	dout := randomDense(rng, r, c, 1.0)
+	l.Backward(dout)
+
+	loss := func() float64 {
+		out := l.Forward(x)
+		out.MulElem(out, dout)
+		return mat.Sum(out)
+	}
+	var diff mat.Dense
+	diff.Sub(l.GetDW(), numericalGradient(loss, w))
+	if d := mat.Norm(&diff, math.Inf(1)); d > 1e-6 {
+		t.Errorf("gradient with respect to W differs from the numerical one by %g", d)
+	}
+}
+
+func TestEmbeddingLayerRejectsInvalidIds(t *testing.T) {
+	l := InitEmbeddingLayer(mat.NewDense(5, 3, nil))
+
+	for _, id := range []float64{-1, 5, 1.5} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Forward accepted id %g", id)
+				}
+			}()
+			l.Forward(mat.NewDense(1, 2, []float64{0, id}))
+		}()
+	}
+}
+
+func TestInitMultiHeadAttentionLayer(t *testing.T) {
+	cases := []struct {
+		name  string
+		w, b  *mat.Dense
+		heads int
+	}{
+		{"heads do not divide modelSize", mat.NewDense(6, 24, nil), mat.NewDense(1, 24, nil), 4},
+		{"wrong weight shape", mat.NewDense(6, 18, nil), mat.NewDense(1, 24, nil), 2},
+		{"wrong bias shape", mat.NewDense(6, 24, nil), mat.NewDense(1, 18, nil), 2},
+	}
+
+	for _, c := range cases {
+		if _, err := InitMultiHeadAttentionLayer(c.w, c.b, 4, c.heads, false); err == nil {
+			t.Errorf("%s: no error", c.name)
+		}
+	}
+}
+
+func TestConv2DTransposeLayerOutputShape(t *testing.T) {
+	w := mat.NewDense(2, 3*3*2, nil)
+	l := InitConv2DTransposeLayer(w, mat.NewDense(1, 3, nil), 2, 3, 4, 3, 2, 2, 1)
+
+	// A Conv2DLayer with the same filter, stride and pad maps 5 x 6 images
+	// back to 3 x 4.
+	channel, height, width := l.OutputShape()
+	if channel != 3 || height != 5 || width != 6 {
+		t.Errorf("OutputShape() = (%d, %d, %d), want (3, 5, 6)", channel, height, width)
+	}
+}
+
+func TestInitGroupNormLayer(t *testing.T) {
+	g, b := make([]float64, 6), make([]float64, 6)
+
+	for _, groups := range []int{1, 2, 3, 6} {
+		if _, err := InitGroupNormLayer(g, b, groups, 4); err != nil {
+			t.Errorf("%d groups of 6 channels: %v", groups, err)
+		}
+	}
+	for _, groups := range []int{0, 4, 12} {
+		if _, err := InitGroupNormLayer(g, b, groups, 4); err == nil {
+			t.Errorf("%d groups of 6 channels: no error", groups)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package layers
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// GroupNormLayer normalizes channel-major feature maps stored one per row.
+// The channels, len(gamma) of them with size values each, are split into
+// groups of consecutive channels, and every group of every sample is
+// normalized on its own, so it does not depend on the batch. Each channel
+// is then scaled and shifted with its gamma and beta.
+type GroupNormLayer struct {
+	gamma  []float64
+	beta   []float64
+	groups int
+	size   int
+	norm   *mat.Dense
+	den    [][]float64
+
+	dgamma []float64
+	dbeta  []float64
+}
+
+// InitGroupNormLayer returns an error unless groups divides the number of
+// channels, len(g).
+func InitGroupNormLayer(g, b []float64, groups, size int) (NormalizationLayer, error) {
+	if groups < 1 || len(g)%groups != 0 {
+		return nil, errors.New("Invalid Args: groups must divide the number of channels")
+	}
+
+	return &GroupNormLayer{
+		gamma:  g,
+		beta:   b,
+		groups: groups,
+		size:   size,
+	}, nil
+}
+
+func (l *GroupNormLayer) GetDGamma() []float64 {
+	return l.dgamma
+}
+
+func (l *GroupNormLayer) GetDBeta() []float64 {
+	return l.dbeta
+}
+
+// groupRange returns the columns [from, to) of group k.
+func (l *GroupNormLayer) groupRange(k int) (from, to int) {
+	width := len(l.gamma) / l.groups * l.size
+	return k * width, (k + 1) * width
+}
+
+func (l *GroupNormLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	l.norm = mat.NewDense(r, c, nil)
+	l.den = make([][]float64, r)
+
+	epsilon := math.Pow10(-7)
+	for i := 0; i < r; i++ {
+		l.den[i] = make([]float64, l.groups)
+		for k := 0; k < l.groups; k++ {
+			from, to := l.groupRange(k)
+			n := float64(to - from)
+
+			mean := 0.0
+			for j := from; j < to; j++ {
+				mean = mean + x.At(i, j)
+			}
+			mean = mean / n
+
+			variance := 0.0
+			for j := from; j < to; j++ {
+				tmp := x.At(i, j) - mean
+				variance = variance + tmp*tmp
+			}
+			variance = variance / n
+			l.den[i][k] = 1.0 / math.Sqrt(variance+epsilon)
+
+			for j := from; j < to; j++ {
+				ch := j / l.size
+				tmp := (x.At(i, j) - mean) * l.den[i][k]
+				l.norm.Set(i, j, tmp)
+				out.Set(i, j, tmp*l.gamma[ch]+l.beta[ch])
+			}
+		}
+	}
+
+	return out
+}
+
+func (l *GroupNormLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	l.dgamma = make([]float64, len(l.gamma))
+	l.dbeta = make([]float64, len(l.beta))
+
+	for i := 0; i < r; i++ {
+		for k := 0; k < l.groups; k++ {
+			from, to := l.groupRange(k)
+			n := float64(to - from)
+
+			sum := 0.0
+			sumNorm := 0.0
+			for j := from; j < to; j++ {
+				ch := j / l.size
+				l.dbeta[ch] = l.dbeta[ch] + dout.At(i, j)
+				l.dgamma[ch] = l.dgamma[ch] + l.norm.At(i, j)*dout.At(i, j)
+				dnorm := dout.At(i, j) * l.gamma[ch]
+				sum = sum + dnorm
+				sumNorm = sumNorm + dnorm*l.norm.At(i, j)
+			}
+			sum = sum / n
+			sumNorm = sumNorm / n
+
+			for j := from; j < to; j++ {
+				dnorm := dout.At(i, j) * l.gamma[j/l.size]
+				dx.Set(i, j, l.den[i][k]*(dnorm-sum-l.norm.At(i, j)*sumNorm))
+			}
+		}
+	}
+
+	return dx
+}
@@ -0,0 +1,201 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type gruCache struct {
+	x     *mat.Dense
+	hprev *mat.Dense
+	rh    *mat.Dense
+	z     *mat.Dense
+	r     *mat.Dense
+	hhat  *mat.Dense
+}
+
+// GRULayer is a gated recurrent unit layer over rows holding sequenceLength
+// steps of inputSize values. W stacks Wx and Wh as
+// (inputSize+hiddenSize, 3*hiddenSize) and B is (1, 3*hiddenSize). Their
+// columns hold, in order, the update gate, the reset gate and the candidate
+// state. The hidden state starts at 0 for every sequence. When
+// returnSequences is true the output holds the hidden state of every step,
+// otherwise only the one of the last step.
+type GRULayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	sequenceLength  int
+	returnSequences bool
+
+	caches []gruCache
+	h      *mat.Dense
+}
+
+func InitGRULayer(w, b *mat.Dense, sequenceLength int, returnSequences bool) *GRULayer {
+	return &GRULayer{
+		W:               w,
+		B:               b,
+		sequenceLength:  sequenceLength,
+		returnSequences: returnSequences,
+	}
+}
+
+func (g *GRULayer) GetDB() *mat.Dense {
+	return g.DB
+}
+
+func (g *GRULayer) GetDW() *mat.Dense {
+	return g.DW
+}
+
+func (g *GRULayer) Forward(x *mat.Dense) *mat.Dense {
+	batchSize, _ := x.Dims()
+	_, c := g.W.Dims()
+	hiddenSize := c / 3
+	wx, wh := splitRecurrentWeight(g.W, hiddenSize)
+	inputSize, _ := wx.Dims()
+	whzr := wh.Slice(0, hiddenSize, 0, 2*hiddenSize)
+	whh := wh.Slice(0, hiddenSize, 2*hiddenSize, 3*hiddenSize)
+
+	g.caches = make([]gruCache, g.sequenceLength)
+	h := mat.NewDense(batchSize, hiddenSize, nil)
+
+	out := mat.NewDense(batchSize, g.sequenceLength*hiddenSize, nil)
+	for t := 0; t < g.sequenceLength; t++ {
+		cache := gruCache{
+			x:     timeStep(x, t, inputSize),
+			hprev: h,
+			rh:    mat.NewDense(batchSize, hiddenSize, nil),
+			z:     mat.NewDense(batchSize, hiddenSize, nil),
+			r:     mat.NewDense(batchSize, hiddenSize, nil),
+			hhat:  mat.NewDense(batchSize, hiddenSize, nil),
+		}
+
+		ax := mat.NewDense(batchSize, 3*hiddenSize, nil)
+		ax.Mul(cache.x, wx)
+		azr := mat.NewDense(batchSize, 2*hiddenSize, nil)
+		azr.Mul(h, whzr)
+
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < hiddenSize; j++ {
+				z := 1 / (1 + math.Exp(-(ax.At(n, j) + azr.At(n, j) + g.B.At(0, j))))
+				r := 1 / (1 + math.Exp(-(ax.At(n, hiddenSize+j) + azr.At(n, hiddenSize+j) + g.B.At(0, hiddenSize+j))))
+				cache.z.Set(n, j, z)
+				cache.r.Set(n, j, r)
+				cache.rh.Set(n, j, r*h.At(n, j))
+			}
+		}
+
+		ah := mat.NewDense(batchSize, hiddenSize, nil)
+		ah.Mul(cache.rh, whh)
+
+		h = mat.NewDense(batchSize, hiddenSize, nil)
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < hiddenSize; j++ {
+				hhat := math.Tanh(ax.At(n, 2*hiddenSize+j) + ah.At(n, j) + g.B.At(0, 2*hiddenSize+j))
+				z := cache.z.At(n, j)
+				cache.hhat.Set(n, j, hhat)
+				h.Set(n, j, (1-z)*cache.hprev.At(n, j)+z*hhat)
+			}
+		}
+
+		g.caches[t] = cache
+		setTimeStep(out, t, h)
+	}
+	g.h = h
+
+	if !g.returnSequences {
+		return g.h
+	}
+
+	return out
+}
+
+func (g *GRULayer) Backward(dout *mat.Dense) *mat.Dense {
+	batchSize, _ := dout.Dims()
+	rw, cw := g.W.Dims()
+	hiddenSize := cw / 3
+	wx, wh := splitRecurrentWeight(g.W, hiddenSize)
+	inputSize := rw - hiddenSize
+	whzr := wh.Slice(0, hiddenSize, 0, 2*hiddenSize)
+	whh := wh.Slice(0, hiddenSize, 2*hiddenSize, 3*hiddenSize)
+
+	g.DW = mat.NewDense(rw, cw, nil)
+	g.DB = mat.NewDense(1, cw, nil)
+	dwx, dwh := splitRecurrentWeight(g.DW, hiddenSize)
+	dwhzr := dwh.Slice(0, hiddenSize, 0, 2*hiddenSize).(*mat.Dense)
+	dwhh := dwh.Slice(0, hiddenSize, 2*hiddenSize, 3*hiddenSize).(*mat.Dense)
+
+	dx := mat.NewDense(batchSize, g.sequenceLength*inputSize, nil)
+	dhnext := mat.NewDense(batchSize, hiddenSize, nil)
+	for t := g.sequenceLength - 1; t >= 0; t-- {
+		cache := g.caches[t]
+
+		dh := mat.DenseCopyOf(dhnext)
+		if g.returnSequences {
+			dh.Add(dh, timeStep(dout, t, hiddenSize))
+		} else if t == g.sequenceLength-1 {
+			dh.Add(dh, dout)
+		}
+
+		da := mat.NewDense(batchSize, 3*hiddenSize, nil)
+		dah := mat.NewDense(batchSize, hiddenSize, nil)
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < hiddenSize; j++ {
+				hhat := cache.hhat.At(n, j)
+				v := dh.At(n, j) * cache.z.At(n, j) * (1 - hhat*hhat)
+				dah.Set(n, j, v)
+				da.Set(n, 2*hiddenSize+j, v)
+			}
+		}
+
+		drh := mat.NewDense(batchSize, hiddenSize, nil)
+		drh.Mul(dah, whh.T())
+
+		dhprev := mat.NewDense(batchSize, hiddenSize, nil)
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < hiddenSize; j++ {
+				z := cache.z.At(n, j)
+				r := cache.r.At(n, j)
+				hprev := cache.hprev.At(n, j)
+				dz := dh.At(n, j) * (cache.hhat.At(n, j) - hprev)
+				dr := drh.At(n, j) * hprev
+				da.Set(n, j, dz*z*(1-z))
+				da.Set(n, hiddenSize+j, dr*r*(1-r))
+				dhprev.Set(n, j, dh.At(n, j)*(1-z)+drh.At(n, j)*r)
+			}
+		}
+
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < cw; j++ {
+				g.DB.Set(0, j, g.DB.At(0, j)+da.At(n, j))
+			}
+		}
+
+		tmp := mat.NewDense(inputSize, cw, nil)
+		tmp.Mul(cache.x.T(), da)
+		dwx.Add(dwx, tmp)
+
+		dazr := da.Slice(0, batchSize, 0, 2*hiddenSize)
+		tmp = mat.NewDense(hiddenSize, 2*hiddenSize, nil)
+		tmp.Mul(cache.hprev.T(), dazr)
+		dwhzr.Add(dwhzr, tmp)
+		tmp = mat.NewDense(hiddenSize, hiddenSize, nil)
+		tmp.Mul(cache.rh.T(), dah)
+		dwhh.Add(dwhh, tmp)
+
+		dxt := mat.NewDense(batchSize, inputSize, nil)
+		dxt.Mul(da, wx.T())
+		setTimeStep(dx, t, dxt)
+
+		tmp = mat.NewDense(batchSize, hiddenSize, nil)
+		tmp.Mul(dazr, whzr.T())
+		dhnext.Add(dhprev, tmp)
+	}
+
+	return dx
+}
@@ -0,0 +1,59 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// HuberLossLayer is quadratic for errors up to delta and linear beyond, and
+// returns the mean over every element.
+type HuberLossLayer struct {
+	delta float64
+	loss  float64
+	y     *mat.Dense
+	t     *mat.Dense
+}
+
+func InitHuberLossLayer(delta float64) *HuberLossLayer {
+	return &HuberLossLayer{
+		delta: delta,
+	}
+}
+
+func (h *HuberLossLayer) Forward(x, t *mat.Dense) float64 {
+	h.t = t
+	h.y = x
+	r, c := x.Dims()
+
+	sum := 0.0
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			e := math.Abs(x.At(i, j) - t.At(i, j))
+			if e <= h.delta {
+				sum = sum + 0.5*e*e
+			} else {
+				sum = sum + h.delta*(e-0.5*h.delta)
+			}
+		}
+	}
+	h.loss = sum / float64(r*c)
+
+	return h.loss
+}
+
+func (h *HuberLossLayer) Backward(dout float64) *mat.Dense {
+	r, c := h.t.Dims()
+	dx := mat.NewDense(r, c, nil)
+
+	size := float64(r * c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			e := h.y.At(i, j) - h.t.At(i, j)
+			e = math.Max(-h.delta, math.Min(h.delta, e))
+			dx.Set(i, j, dout*e/size)
+		}
+	}
+
+	return dx
+}
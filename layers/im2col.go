@@ -0,0 +1,77 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+func convOutputSize(size, filterSize, stride, pad int) int {
+	return (size+2*pad-filterSize)/stride + 1
+}
+
+// im2col expands each row of x, a channel-major image of c*h*w values, into
+// one row per output position holding the c*fh*fw values under the filter.
+func im2col(x *mat.Dense, c, h, w, fh, fw, sh, sw, ph, pw int) *mat.Dense {
+	n, _ := x.Dims()
+	oh := convOutputSize(h, fh, sh, ph)
+	ow := convOutputSize(w, fw, sw, pw)
+	col := mat.NewDense(n*oh*ow, c*fh*fw, nil)
+
+	for i := 0; i < n; i++ {
+		for oy := 0; oy < oh; oy++ {
+			for ox := 0; ox < ow; ox++ {
+				row := (i*oh+oy)*ow + ox
+				for ch := 0; ch < c; ch++ {
+					for ky := 0; ky < fh; ky++ {
+						y := oy*sh + ky - ph
+						if y < 0 || y >= h {
+							continue
+						}
+						for kx := 0; kx < fw; kx++ {
+							xx := ox*sw + kx - pw
+							if xx < 0 || xx >= w {
+								continue
+							}
+							col.Set(row, (ch*fh+ky)*fw+kx, x.At(i, (ch*h+y)*w+xx))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return col
+}
+
+// col2im is the adjoint of im2col: it adds every value of col back onto the
+// image position it was copied from.
+func col2im(col *mat.Dense, n, c, h, w, fh, fw, sh, sw, ph, pw int) *mat.Dense {
+	oh := convOutputSize(h, fh, sh, ph)
+	ow := convOutputSize(w, fw, sw, pw)
+	x := mat.NewDense(n, c*h*w, nil)
+
+	for i := 0; i < n; i++ {
+		for oy := 0; oy < oh; oy++ {
+			for ox := 0; ox < ow; ox++ {
+				row := (i*oh+oy)*ow + ox
+				for ch := 0; ch < c; ch++ {
+					for ky := 0; ky < fh; ky++ {
+						y := oy*sh + ky - ph
+						if y < 0 || y >= h {
+							continue
+						}
+						for kx := 0; kx < fw; kx++ {
+							xx := ox*sw + kx - pw
+							if xx < 0 || xx >= w {
+								continue
+							}
+							j := (ch*h+y)*w + xx
+							x.Set(i, j, x.At(i, j)+col.At(row, (ch*fh+ky)*fw+kx))
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return x
+}
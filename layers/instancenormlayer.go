@@ -0,0 +1,18 @@
+package layers
+
+// InstanceNormLayer normalizes every channel of every sample on its own. It
+// is a GroupNormLayer with one channel per group.
+type InstanceNormLayer struct {
+	GroupNormLayer
+}
+
+func InitInstanceNormLayer(g, b []float64, size int) NormalizationLayer {
+	return &InstanceNormLayer{
+		GroupNormLayer: GroupNormLayer{
+			gamma:  g,
+			beta:   b,
+			groups: len(g),
+			size:   size,
+		},
+	}
+}
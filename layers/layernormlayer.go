@@ -0,0 +1,95 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// LayerNormLayer normalizes each sample across its features, so it behaves
+// the same whatever the batch size, and then scales and shifts every feature
+// with gamma and beta.
+type LayerNormLayer struct {
+	gamma []float64
+	beta  []float64
+	norm  *mat.Dense
+	den   []float64
+
+	dgamma []float64
+	dbeta  []float64
+}
+
+func InitLayerNormLayer(g, b []float64) NormalizationLayer {
+	return &LayerNormLayer{
+		gamma: g,
+		beta:  b,
+	}
+}
+
+func (l *LayerNormLayer) GetDGamma() []float64 {
+	return l.dgamma
+}
+
+func (l *LayerNormLayer) GetDBeta() []float64 {
+	return l.dbeta
+}
+
+func (l *LayerNormLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	l.norm = mat.NewDense(r, c, nil)
+	l.den = make([]float64, r)
+
+	epsilon := math.Pow10(-7)
+	for i := 0; i < r; i++ {
+		mean := 0.0
+		for j := 0; j < c; j++ {
+			mean = mean + x.At(i, j)
+		}
+		mean = mean / float64(c)
+
+		variance := 0.0
+		for j := 0; j < c; j++ {
+			tmp := x.At(i, j) - mean
+			variance = variance + tmp*tmp
+		}
+		variance = variance / float64(c)
+		l.den[i] = 1.0 / math.Sqrt(variance+epsilon)
+
+		for j := 0; j < c; j++ {
+			tmp := (x.At(i, j) - mean) * l.den[i]
+			l.norm.Set(i, j, tmp)
+			out.Set(i, j, tmp*l.gamma[j]+l.beta[j])
+		}
+	}
+
+	return out
+}
+
+func (l *LayerNormLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	l.dgamma = make([]float64, c)
+	l.dbeta = make([]float64, c)
+
+	for i := 0; i < r; i++ {
+		sum := 0.0
+		sumNorm := 0.0
+		for j := 0; j < c; j++ {
+			l.dbeta[j] = l.dbeta[j] + dout.At(i, j)
+			l.dgamma[j] = l.dgamma[j] + l.norm.At(i, j)*dout.At(i, j)
+			dnorm := dout.At(i, j) * l.gamma[j]
+			sum = sum + dnorm
+			sumNorm = sumNorm + dnorm*l.norm.At(i, j)
+		}
+		sum = sum / float64(c)
+		sumNorm = sumNorm / float64(c)
+
+		for j := 0; j < c; j++ {
+			dnorm := dout.At(i, j) * l.gamma[j]
+			dx.Set(i, j, l.den[i]*(dnorm-sum-l.norm.At(i, j)*sumNorm))
+		}
+	}
+
+	return dx
+}
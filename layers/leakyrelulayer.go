@@ -0,0 +1,55 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// LeakyReLuLayer passes positive inputs through and multiplies the others
+// by slope.
+type LeakyReLuLayer struct {
+	slope float64
+	mask  [][]bool
+}
+
+func InitLeakyReLuLayer(slope float64) ActivationLayer {
+	return &LeakyReLuLayer{
+		slope: slope,
+	}
+}
+
+func (l *LeakyReLuLayer) Forward(x *mat.Dense) *mat.Dense {
+	rows, cols := x.Dims()
+	out := mat.NewDense(rows, cols, nil)
+	l.mask = make([][]bool, rows)
+	for i := 0; i < rows; i++ {
+		l.mask[i] = make([]bool, cols)
+		for j := 0; j < cols; j++ {
+			v := x.At(i, j)
+			if v > 0 {
+				l.mask[i][j] = true
+				out.Set(i, j, v)
+			} else {
+				out.Set(i, j, v*l.slope)
+			}
+		}
+	}
+
+	return out
+}
+
+func (l *LeakyReLuLayer) Backward(dout *mat.Dense) *mat.Dense {
+	rows, cols := dout.Dims()
+	dx := mat.NewDense(rows, cols, nil)
+
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if l.mask[i][j] {
+				dx.Set(i, j, dout.At(i, j))
+			} else {
+				dx.Set(i, j, dout.At(i, j)*l.slope)
+			}
+		}
+	}
+
+	return dx
+}
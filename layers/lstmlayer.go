@@ -0,0 +1,181 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type lstmCache struct {
+	x     *mat.Dense
+	hprev *mat.Dense
+	cprev *mat.Dense
+	c     *mat.Dense
+	f     *mat.Dense
+	g     *mat.Dense
+	i     *mat.Dense
+	o     *mat.Dense
+}
+
+// LSTMLayer is a long short-term memory layer over rows holding
+// sequenceLength steps of inputSize values. W stacks Wx and Wh as
+// (inputSize+hiddenSize, 4*hiddenSize) and B is (1, 4*hiddenSize). Their
+// columns hold, in order, the forget gate, the cell candidate, the input
+// gate and the output gate. The hidden and cell states start at 0 for
+// every sequence. When returnSequences is true the output holds the hidden
+// state of every step, otherwise only the one of the last step.
+type LSTMLayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	sequenceLength  int
+	returnSequences bool
+
+	caches []lstmCache
+	h      *mat.Dense
+}
+
+func InitLSTMLayer(w, b *mat.Dense, sequenceLength int, returnSequences bool) *LSTMLayer {
+	return &LSTMLayer{
+		W:               w,
+		B:               b,
+		sequenceLength:  sequenceLength,
+		returnSequences: returnSequences,
+	}
+}
+
+func (l *LSTMLayer) GetDB() *mat.Dense {
+	return l.DB
+}
+
+func (l *LSTMLayer) GetDW() *mat.Dense {
+	return l.DW
+}
+
+func (l *LSTMLayer) Forward(x *mat.Dense) *mat.Dense {
+	batchSize, _ := x.Dims()
+	_, c := l.W.Dims()
+	hiddenSize := c / 4
+	wx, wh := splitRecurrentWeight(l.W, hiddenSize)
+	inputSize, _ := wx.Dims()
+
+	l.caches = make([]lstmCache, l.sequenceLength)
+	h := mat.NewDense(batchSize, hiddenSize, nil)
+	cell := mat.NewDense(batchSize, hiddenSize, nil)
+
+	out := mat.NewDense(batchSize, l.sequenceLength*hiddenSize, nil)
+	for t := 0; t < l.sequenceLength; t++ {
+		cache := lstmCache{
+			x:     timeStep(x, t, inputSize),
+			hprev: h,
+			cprev: cell,
+			f:     mat.NewDense(batchSize, hiddenSize, nil),
+			g:     mat.NewDense(batchSize, hiddenSize, nil),
+			i:     mat.NewDense(batchSize, hiddenSize, nil),
+			o:     mat.NewDense(batchSize, hiddenSize, nil),
+		}
+
+		a := mat.NewDense(batchSize, 4*hiddenSize, nil)
+		tmp := mat.NewDense(batchSize, 4*hiddenSize, nil)
+		a.Mul(cache.x, wx)
+		tmp.Mul(h, wh)
+		a.Add(a, tmp)
+
+		h = mat.NewDense(batchSize, hiddenSize, nil)
+		cell = mat.NewDense(batchSize, hiddenSize, nil)
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < hiddenSize; j++ {
+				f := 1 / (1 + math.Exp(-(a.At(n, j) + l.B.At(0, j))))
+				g := math.Tanh(a.At(n, hiddenSize+j) + l.B.At(0, hiddenSize+j))
+				i := 1 / (1 + math.Exp(-(a.At(n, 2*hiddenSize+j) + l.B.At(0, 2*hiddenSize+j))))
+				o := 1 / (1 + math.Exp(-(a.At(n, 3*hiddenSize+j) + l.B.At(0, 3*hiddenSize+j))))
+				cache.f.Set(n, j, f)
+				cache.g.Set(n, j, g)
+				cache.i.Set(n, j, i)
+				cache.o.Set(n, j, o)
+
+				cv := f*cache.cprev.At(n, j) + g*i
+				cell.Set(n, j, cv)
+				h.Set(n, j, o*math.Tanh(cv))
+			}
+		}
+
+		cache.c = cell
+		l.caches[t] = cache
+		setTimeStep(out, t, h)
+	}
+	l.h = h
+
+	if !l.returnSequences {
+		return l.h
+	}
+
+	return out
+}
+
+func (l *LSTMLayer) Backward(dout *mat.Dense) *mat.Dense {
+	batchSize, _ := dout.Dims()
+	rw, cw := l.W.Dims()
+	hiddenSize := cw / 4
+	wx, wh := splitRecurrentWeight(l.W, hiddenSize)
+	inputSize := rw - hiddenSize
+
+	l.DW = mat.NewDense(rw, cw, nil)
+	l.DB = mat.NewDense(1, cw, nil)
+	dwx, dwh := splitRecurrentWeight(l.DW, hiddenSize)
+
+	dx := mat.NewDense(batchSize, l.sequenceLength*inputSize, nil)
+	dhnext := mat.NewDense(batchSize, hiddenSize, nil)
+	dcnext := mat.NewDense(batchSize, hiddenSize, nil)
+	for t := l.sequenceLength - 1; t >= 0; t-- {
+		cache := l.caches[t]
+
+		dh := mat.DenseCopyOf(dhnext)
+		if l.returnSequences {
+			dh.Add(dh, timeStep(dout, t, hiddenSize))
+		} else if t == l.sequenceLength-1 {
+			dh.Add(dh, dout)
+		}
+
+		da := mat.NewDense(batchSize, 4*hiddenSize, nil)
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < hiddenSize; j++ {
+				f := cache.f.At(n, j)
+				g := cache.g.At(n, j)
+				i := cache.i.At(n, j)
+				o := cache.o.At(n, j)
+				tanhc := math.Tanh(cache.c.At(n, j))
+
+				ds := dcnext.At(n, j) + dh.At(n, j)*o*(1-tanhc*tanhc)
+				dcnext.Set(n, j, ds*f)
+
+				da.Set(n, j, ds*cache.cprev.At(n, j)*f*(1-f))
+				da.Set(n, hiddenSize+j, ds*i*(1-g*g))
+				da.Set(n, 2*hiddenSize+j, ds*g*i*(1-i))
+				da.Set(n, 3*hiddenSize+j, dh.At(n, j)*tanhc*o*(1-o))
+			}
+		}
+
+		for n := 0; n < batchSize; n++ {
+			for j := 0; j < cw; j++ {
+				l.DB.Set(0, j, l.DB.At(0, j)+da.At(n, j))
+			}
+		}
+
+		tmp := mat.NewDense(inputSize, cw, nil)
+		tmp.Mul(cache.x.T(), da)
+		dwx.Add(dwx, tmp)
+		tmp = mat.NewDense(hiddenSize, cw, nil)
+		tmp.Mul(cache.hprev.T(), da)
+		dwh.Add(dwh, tmp)
+
+		dxt := mat.NewDense(batchSize, inputSize, nil)
+		dxt.Mul(da, wx.T())
+		setTimeStep(dx, t, dxt)
+		dhnext.Mul(da, wh.T())
+	}
+
+	return dx
+}
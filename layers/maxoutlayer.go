@@ -0,0 +1,88 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// MaxoutLayer is an affine layer with pieces affine pieces per output unit,
+// of which it outputs the largest. W is (inputSize, pieces*outputSize) and
+// B is (1, pieces*outputSize), and the piece p of unit j is column
+// p*outputSize+j.
+type MaxoutLayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	X  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	pieces int
+	argmax [][]int
+}
+
+func InitMaxoutLayer(w, b *mat.Dense, pieces int) *MaxoutLayer {
+	return &MaxoutLayer{
+		W:      w,
+		B:      b,
+		pieces: pieces,
+	}
+}
+
+func (m *MaxoutLayer) GetDB() *mat.Dense {
+	return m.DB
+}
+
+func (m *MaxoutLayer) GetDW() *mat.Dense {
+	return m.DW
+}
+
+func (m *MaxoutLayer) Forward(x *mat.Dense) *mat.Dense {
+	m.X = x
+	batchSize, _ := x.Dims()
+	_, c := m.W.Dims()
+	outputSize := c / m.pieces
+
+	z := mat.NewDense(batchSize, c, nil)
+	z.Mul(x, m.W)
+
+	out := mat.NewDense(batchSize, outputSize, nil)
+	m.argmax = make([][]int, batchSize)
+	for i := 0; i < batchSize; i++ {
+		m.argmax[i] = make([]int, outputSize)
+		for j := 0; j < outputSize; j++ {
+			argmax := j
+			for p := 1; p < m.pieces; p++ {
+				k := p*outputSize + j
+				if z.At(i, k)+m.B.At(0, k) > z.At(i, argmax)+m.B.At(0, argmax) {
+					argmax = k
+				}
+			}
+			m.argmax[i][j] = argmax
+			out.Set(i, j, z.At(i, argmax)+m.B.At(0, argmax))
+		}
+	}
+
+	return out
+}
+
+func (m *MaxoutLayer) Backward(dout *mat.Dense) *mat.Dense {
+	batchSize, outputSize := dout.Dims()
+	r, c := m.W.Dims()
+
+	dz := mat.NewDense(batchSize, c, nil)
+	m.DB = mat.NewDense(1, c, nil)
+	for i := 0; i < batchSize; i++ {
+		for j := 0; j < outputSize; j++ {
+			k := m.argmax[i][j]
+			dz.Set(i, k, dout.At(i, j))
+			m.DB.Set(0, k, m.DB.At(0, k)+dout.At(i, j))
+		}
+	}
+
+	m.DW = mat.NewDense(r, c, nil)
+	m.DW.Mul(m.X.T(), dz)
+
+	dx := mat.NewDense(batchSize, r, nil)
+	dx.Mul(dz, m.W.T())
+
+	return dx
+}
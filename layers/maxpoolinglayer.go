@@ -0,0 +1,101 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// MaxPoolingLayer downsamples channel-major feature maps stored one per row
+// by taking the maximum of each poolHeight x poolWidth window. Padded cells
+// never take part in the maximum.
+type MaxPoolingLayer struct {
+	channel    int
+	height     int
+	width      int
+	poolHeight int
+	poolWidth  int
+	stride     int
+	pad        int
+
+	batchSize int
+	argmax    []int
+}
+
+func InitMaxPoolingLayer(channel, height, width, poolHeight, poolWidth, stride, pad int) *MaxPoolingLayer {
+	return &MaxPoolingLayer{
+		channel:    channel,
+		height:     height,
+		width:      width,
+		poolHeight: poolHeight,
+		poolWidth:  poolWidth,
+		stride:     stride,
+		pad:        pad,
+	}
+}
+
+func (m *MaxPoolingLayer) OutputShape() (channel, height, width int) {
+	channel = m.channel
+	height = convOutputSize(m.height, m.poolHeight, m.stride, m.pad)
+	width = convOutputSize(m.width, m.poolWidth, m.stride, m.pad)
+	return
+}
+
+// inImage reports whether the k-th cell of the window at output position p
+// lies inside the image rather than in the padding.
+func (m *MaxPoolingLayer) inImage(p, k int) bool {
+	_, _, ow := m.OutputShape()
+	y := (p/ow)*m.stride + k/m.poolWidth - m.pad
+	x := (p%ow)*m.stride + k%m.poolWidth - m.pad
+	return y >= 0 && y < m.height && x >= 0 && x < m.width
+}
+
+func (m *MaxPoolingLayer) Forward(x *mat.Dense) *mat.Dense {
+	m.batchSize, _ = x.Dims()
+	_, oh, ow := m.OutputShape()
+	size := oh * ow
+	window := m.poolHeight * m.poolWidth
+
+	col := im2col(x, m.channel, m.height, m.width, m.poolHeight, m.poolWidth, m.stride, m.stride, m.pad, m.pad)
+	out := mat.NewDense(m.batchSize, m.channel*size, nil)
+	m.argmax = make([]int, m.batchSize*m.channel*size)
+
+	for i := 0; i < m.batchSize; i++ {
+		for p := 0; p < size; p++ {
+			for ch := 0; ch < m.channel; ch++ {
+				argmax := -1
+				max := math.Inf(-1)
+				for k := 0; k < window; k++ {
+					if m.inImage(p, k) && col.At(i*size+p, ch*window+k) > max {
+						argmax = ch*window + k
+						max = col.At(i*size+p, argmax)
+					}
+				}
+				m.argmax[i*m.channel*size+ch*size+p] = argmax
+				if argmax >= 0 {
+					out.Set(i, ch*size+p, max)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+func (m *MaxPoolingLayer) Backward(dout *mat.Dense) *mat.Dense {
+	_, oh, ow := m.OutputShape()
+	size := oh * ow
+
+	dcol := mat.NewDense(m.batchSize*size, m.channel*m.poolHeight*m.poolWidth, nil)
+	for i := 0; i < m.batchSize; i++ {
+		for p := 0; p < size; p++ {
+			for ch := 0; ch < m.channel; ch++ {
+				if argmax := m.argmax[i*m.channel*size+ch*size+p]; argmax >= 0 {
+					dcol.Set(i*size+p, argmax, dout.At(i, ch*size+p))
+				}
+			}
+		}
+	}
+
+	return col2im(dcol, m.batchSize, m.channel, m.height, m.width, m.poolHeight, m.poolWidth, m.stride, m.stride, m.pad, m.pad)
+}
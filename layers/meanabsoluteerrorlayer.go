@@ -0,0 +1,53 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// MeanAbsoluteErrorLayer returns the mean of |x-t| over every element.
+type MeanAbsoluteErrorLayer struct {
+	loss float64
+	y    *mat.Dense
+	t    *mat.Dense
+}
+
+func InitMeanAbsoluteErrorLayer() *MeanAbsoluteErrorLayer {
+	return &MeanAbsoluteErrorLayer{}
+}
+
+func (m *MeanAbsoluteErrorLayer) Forward(x, t *mat.Dense) float64 {
+	m.t = t
+	m.y = x
+	r, c := x.Dims()
+
+	sum := 0.0
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			sum = sum + math.Abs(x.At(i, j)-t.At(i, j))
+		}
+	}
+	m.loss = sum / float64(r*c)
+
+	return m.loss
+}
+
+func (m *MeanAbsoluteErrorLayer) Backward(dout float64) *mat.Dense {
+	r, c := m.t.Dims()
+	dx := mat.NewDense(r, c, nil)
+
+	size := float64(r * c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			e := m.y.At(i, j) - m.t.At(i, j)
+			if e > 0 {
+				dx.Set(i, j, dout/size)
+			} else if e < 0 {
+				dx.Set(i, j, -dout/size)
+			}
+		}
+	}
+
+	return dx
+}
@@ -0,0 +1,47 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// MeanSquaredErrorLayer returns the mean of (x-t)^2 over every element.
+type MeanSquaredErrorLayer struct {
+	loss float64
+	y    *mat.Dense
+	t    *mat.Dense
+}
+
+func InitMeanSquaredErrorLayer() *MeanSquaredErrorLayer {
+	return &MeanSquaredErrorLayer{}
+}
+
+func (m *MeanSquaredErrorLayer) Forward(x, t *mat.Dense) float64 {
+	m.t = t
+	m.y = x
+	r, c := x.Dims()
+
+	sum := 0.0
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			e := x.At(i, j) - t.At(i, j)
+			sum = sum + e*e
+		}
+	}
+	m.loss = sum / float64(r*c)
+
+	return m.loss
+}
+
+func (m *MeanSquaredErrorLayer) Backward(dout float64) *mat.Dense {
+	r, c := m.t.Dims()
+	dx := mat.NewDense(r, c, nil)
+
+	size := float64(r * c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			dx.Set(i, j, dout*2*(m.y.At(i, j)-m.t.At(i, j))/size)
+		}
+	}
+
+	return dx
+}
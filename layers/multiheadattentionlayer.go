@@ -0,0 +1,223 @@
+package layers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// MultiHeadAttentionLayer is scaled dot-product self-attention with heads
+// heads. Its input has one token of modelSize values per row, and every
+// sequenceLength consecutive rows form one sequence, which is the layout
+// SequenceToBatchLayer produces. AffineLayer, LayerNormLayer and AddLayer
+// therefore apply to every token on their own, so that a transformer
+// encoder block can be built from them around this layer.
+//
+// W holds the query, key, value and output projections side by side as
+// (modelSize, 4*modelSize) and B their biases as (1, 4*modelSize). When
+// causal is true a token only attends to itself and the tokens before it.
+type MultiHeadAttentionLayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	sequenceLength int
+	heads          int
+	causal         bool
+
+	x         *mat.Dense
+	q         *mat.Dense
+	k         *mat.Dense
+	v         *mat.Dense
+	attention [][]*mat.Dense
+	concat    *mat.Dense
+}
+
+// InitMultiHeadAttentionLayer returns an error unless W and B have the
+// shapes above and heads divides modelSize.
+func InitMultiHeadAttentionLayer(w, b *mat.Dense, sequenceLength, heads int, causal bool) (*MultiHeadAttentionLayer, error) {
+	modelSize, c := w.Dims()
+	if c != 4*modelSize {
+		return nil, errors.New("Invalid Args: W must be (modelSize, 4*modelSize)")
+	}
+	if rb, cb := b.Dims(); rb != 1 || cb != c {
+		return nil, errors.New("Invalid Args: B must be (1, 4*modelSize)")
+	}
+	if sequenceLength < 1 || heads < 1 || modelSize%heads != 0 {
+		return nil, errors.New("Invalid Args: heads must divide modelSize")
+	}
+
+	return &MultiHeadAttentionLayer{
+		W:              w,
+		B:              b,
+		sequenceLength: sequenceLength,
+		heads:          heads,
+		causal:         causal,
+	}, nil
+}
+
+func (m *MultiHeadAttentionLayer) GetDB() *mat.Dense {
+	return m.DB
+}
+
+func (m *MultiHeadAttentionLayer) GetDW() *mat.Dense {
+	return m.DW
+}
+
+// projection returns views of the weight and bias of projection p, which is
+// 0 for the query, 1 for the key, 2 for the value and 3 for the output.
+func projection(w, b *mat.Dense, p int) (pw, pb *mat.Dense) {
+	size, _ := w.Dims()
+	pw = w.Slice(0, size, p*size, (p+1)*size).(*mat.Dense)
+	pb = b.Slice(0, 1, p*size, (p+1)*size).(*mat.Dense)
+	return
+}
+
+func project(x, w, b *mat.Dense) *mat.Dense {
+	r, _ := x.Dims()
+	_, c := w.Dims()
+	out := mat.NewDense(r, c, nil)
+	out.Mul(x, w)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			out.Set(i, j, out.At(i, j)+b.At(0, j))
+		}
+	}
+	return out
+}
+
+// headView returns the rows of sequence n and the columns of head h of x.
+func (m *MultiHeadAttentionLayer) headView(x *mat.Dense, n, h int) *mat.Dense {
+	_, size := x.Dims()
+	headSize := size / m.heads
+	return x.Slice(n*m.sequenceLength, (n+1)*m.sequenceLength, h*headSize, (h+1)*headSize).(*mat.Dense)
+}
+
+func (m *MultiHeadAttentionLayer) Forward(x *mat.Dense) *mat.Dense {
+	rows, size := x.Dims()
+	if modelSize, _ := m.W.Dims(); size != modelSize || rows%m.sequenceLength != 0 {
+		panic(fmt.Sprintf("layers: input of %d x %d is not sequences of %d tokens of %d values", rows, size, m.sequenceLength, modelSize))
+	}
+	batchSize := rows / m.sequenceLength
+	headSize := size / m.heads
+	scale := 1 / math.Sqrt(float64(headSize))
+
+	m.x = x
+	wq, bq := projection(m.W, m.B, 0)
+	wk, bk := projection(m.W, m.B, 1)
+	wv, bv := projection(m.W, m.B, 2)
+	wo, bo := projection(m.W, m.B, 3)
+	m.q = project(x, wq, bq)
+	m.k = project(x, wk, bk)
+	m.v = project(x, wv, bv)
+
+	m.concat = mat.NewDense(rows, size, nil)
+	m.attention = make([][]*mat.Dense, batchSize)
+	for n := 0; n < batchSize; n++ {
+		m.attention[n] = make([]*mat.Dense, m.heads)
+		for h := 0; h < m.heads; h++ {
+			scores := mat.NewDense(m.sequenceLength, m.sequenceLength, nil)
+			scores.Mul(m.headView(m.q, n, h), m.headView(m.k, n, h).T())
+			scores.Scale(scale, scores)
+
+			a := mat.NewDense(m.sequenceLength, m.sequenceLength, nil)
+			for i := 0; i < m.sequenceLength; i++ {
+				last := m.sequenceLength
+				if m.causal {
+					last = i + 1
+				}
+				max := scores.At(i, 0)
+				for j := 1; j < last; j++ {
+					max = math.Max(max, scores.At(i, j))
+				}
+				sum := 0.0
+				for j := 0; j < last; j++ {
+					a.Set(i, j, math.Exp(scores.At(i, j)-max))
+					sum = sum + a.At(i, j)
+				}
+				for j := 0; j < last; j++ {
+					a.Set(i, j, a.At(i, j)/sum)
+				}
+			}
+			m.attention[n][h] = a
+
+			m.headView(m.concat, n, h).Mul(a, m.headView(m.v, n, h))
+		}
+	}
+
+	return project(m.concat, wo, bo)
+}
+
+func (m *MultiHeadAttentionLayer) Backward(dout *mat.Dense) *mat.Dense {
+	rows, size := dout.Dims()
+	batchSize := rows / m.sequenceLength
+	headSize := size / m.heads
+	scale := 1 / math.Sqrt(float64(headSize))
+
+	m.DW = mat.NewDense(size, 4*size, nil)
+	m.DB = mat.NewDense(1, 4*size, nil)
+
+	wo, _ := projection(m.W, m.B, 3)
+	dwo, dbo := projection(m.DW, m.DB, 3)
+	dwo.Mul(m.concat.T(), dout)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < size; j++ {
+			dbo.Set(0, j, dbo.At(0, j)+dout.At(i, j))
+		}
+	}
+
+	dconcat := mat.NewDense(rows, size, nil)
+	dconcat.Mul(dout, wo.T())
+
+	dq := mat.NewDense(rows, size, nil)
+	dk := mat.NewDense(rows, size, nil)
+	dv := mat.NewDense(rows, size, nil)
+	for n := 0; n < batchSize; n++ {
+		for h := 0; h < m.heads; h++ {
+			a := m.attention[n][h]
+			dhead := m.headView(dconcat, n, h)
+
+			m.headView(dv, n, h).Mul(a.T(), dhead)
+
+			da := mat.NewDense(m.sequenceLength, m.sequenceLength, nil)
+			da.Mul(dhead, m.headView(m.v, n, h).T())
+
+			// Softmax backward, folding in the scale of the scores. Masked
+			// entries have a weight of 0 and so get no gradient.
+			dscores := mat.NewDense(m.sequenceLength, m.sequenceLength, nil)
+			for i := 0; i < m.sequenceLength; i++ {
+				sum := 0.0
+				for j := 0; j < m.sequenceLength; j++ {
+					sum = sum + da.At(i, j)*a.At(i, j)
+				}
+				for j := 0; j < m.sequenceLength; j++ {
+					dscores.Set(i, j, a.At(i, j)*(da.At(i, j)-sum)*scale)
+				}
+			}
+
+			m.headView(dq, n, h).Mul(dscores, m.headView(m.k, n, h))
+			m.headView(dk, n, h).Mul(dscores.T(), m.headView(m.q, n, h))
+		}
+	}
+
+	dx := mat.NewDense(rows, size, nil)
+	for p, d := range []*mat.Dense{dq, dk, dv} {
+		w, _ := projection(m.W, m.B, p)
+		dw, db := projection(m.DW, m.DB, p)
+		dw.Mul(m.x.T(), d)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < size; j++ {
+				db.Set(0, j, db.At(0, j)+d.At(i, j))
+			}
+		}
+
+		tmp := mat.NewDense(rows, size, nil)
+		tmp.Mul(d, w.T())
+		dx.Add(dx, tmp)
+	}
+
+	return dx
+}
@@ -6,10 +6,18 @@ import (
 
 type NoNormalizationLayer struct{}
 
-func InitNoNormalizationLayer(b, g []float64) NormalizationLayer {
+func InitNoNormalizationLayer(g, b []float64) NormalizationLayer {
 	return &NoNormalizationLayer{}
 }
 
+func (n *NoNormalizationLayer) GetDGamma() []float64 {
+	return nil
+}
+
+func (n *NoNormalizationLayer) GetDBeta() []float64 {
+	return nil
+}
+
 func (n *NoNormalizationLayer) Forward(x *mat.Dense) *mat.Dense {
 	return x
 }
@@ -5,4 +5,6 @@ import "gonum.org/v1/gonum/mat"
 type NormalizationLayer interface {
 	Forward(*mat.Dense) *mat.Dense
 	Backward(*mat.Dense) *mat.Dense
+	GetDGamma() []float64
+	GetDBeta() []float64
 }
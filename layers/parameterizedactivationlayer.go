@@ -0,0 +1,8 @@
+package layers
+
+// ParameterizedActivationLayer is an ActivationLayer with learned
+// parameters, whose gradient GetDAlpha returns after Backward.
+type ParameterizedActivationLayer interface {
+	ActivationLayer
+	GetDAlpha() []float64
+}
@@ -0,0 +1,65 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// PReLuLayer passes positive inputs through and multiplies the others by a
+// learned slope. Rows hold len(alpha) channels of size values each, in
+// channel-major order, and every channel has its own slope.
+type PReLuLayer struct {
+	alpha  []float64
+	size   int
+	x      *mat.Dense
+	dalpha []float64
+}
+
+func InitPReLuLayer(alpha []float64, size int) *PReLuLayer {
+	return &PReLuLayer{
+		alpha: alpha,
+		size:  size,
+	}
+}
+
+func (p *PReLuLayer) GetDAlpha() []float64 {
+	return p.dalpha
+}
+
+func (p *PReLuLayer) Forward(x *mat.Dense) *mat.Dense {
+	rows, cols := x.Dims()
+	out := mat.NewDense(rows, cols, nil)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			v := x.At(i, j)
+			if v > 0 {
+				out.Set(i, j, v)
+			} else {
+				out.Set(i, j, v*p.alpha[j/p.size])
+			}
+		}
+	}
+	p.x = mat.DenseCopyOf(x)
+
+	return out
+}
+
+func (p *PReLuLayer) Backward(dout *mat.Dense) *mat.Dense {
+	rows, cols := dout.Dims()
+	dx := mat.NewDense(rows, cols, nil)
+	p.dalpha = make([]float64, len(p.alpha))
+
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			v := p.x.At(i, j)
+			if v > 0 {
+				dx.Set(i, j, dout.At(i, j))
+			} else {
+				ch := j / p.size
+				dx.Set(i, j, dout.At(i, j)*p.alpha[ch])
+				p.dalpha[ch] = p.dalpha[ch] + dout.At(i, j)*v
+			}
+		}
+	}
+
+	return dx
+}
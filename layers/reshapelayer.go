@@ -0,0 +1,46 @@
+package layers
+
+import (
+	"errors"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ReshapeLayer reinterprets samples of inputShape as samples of
+// outputShape. The rows already hold the values in row-major order, so the
+// data is passed through unchanged and only the shape changes.
+type ReshapeLayer struct {
+	inputShape  Shape
+	outputShape Shape
+}
+
+func InitReshapeLayer(inputShape, outputShape Shape) (*ReshapeLayer, error) {
+	if inputShape.Size() != outputShape.Size() {
+		return nil, errors.New("Invalid Args: the input and output shapes differ in size")
+	}
+
+	return &ReshapeLayer{
+		inputShape:  inputShape,
+		outputShape: outputShape,
+	}, nil
+}
+
+func (r *ReshapeLayer) InputShape() Shape {
+	return r.inputShape
+}
+
+func (r *ReshapeLayer) OutputShape() Shape {
+	return r.outputShape
+}
+
+func (r *ReshapeLayer) Forward(x *mat.Dense) *mat.Dense {
+	if _, c := x.Dims(); c != r.inputShape.Size() {
+		panic(fmt.Sprintf("layers: input of %d values does not match shape %v", c, r.inputShape))
+	}
+	return x
+}
+
+func (r *ReshapeLayer) Backward(dout *mat.Dense) *mat.Dense {
+	return dout
+}
@@ -0,0 +1,146 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// timeStep copies the size values of step t out of every row of x, which
+// holds a sequence of steps laid out one after another.
+func timeStep(x *mat.Dense, t, size int) *mat.Dense {
+	r, _ := x.Dims()
+	return mat.DenseCopyOf(x.Slice(0, r, t*size, (t+1)*size))
+}
+
+// setTimeStep writes v into the step t of every row of x.
+func setTimeStep(x *mat.Dense, t int, v *mat.Dense) {
+	r, size := v.Dims()
+	x.Slice(0, r, t*size, (t+1)*size).(*mat.Dense).Copy(v)
+}
+
+// splitRecurrentWeight returns views of the input part (inputSize rows) and
+// the recurrent part (hiddenSize rows) of a weight stacked as [Wx; Wh].
+func splitRecurrentWeight(w *mat.Dense, hiddenSize int) (wx, wh *mat.Dense) {
+	r, c := w.Dims()
+	wx = w.Slice(0, r-hiddenSize, 0, c).(*mat.Dense)
+	wh = w.Slice(r-hiddenSize, r, 0, c).(*mat.Dense)
+	return
+}
+
+// RNNLayer is an Elman recurrent layer h_t = tanh(x_t Wx + h_t-1 Wh + b)
+// over rows holding sequenceLength steps of inputSize values. W stacks Wx
+// and Wh as (inputSize+hiddenSize, hiddenSize) and B is (1, hiddenSize).
+// The hidden state starts at 0 for every sequence. When returnSequences is
+// true the output holds the hidden state of every step, otherwise only the
+// one of the last step.
+type RNNLayer struct {
+	W  *mat.Dense
+	B  *mat.Dense
+	DW *mat.Dense
+	DB *mat.Dense
+
+	sequenceLength  int
+	returnSequences bool
+
+	xs []*mat.Dense
+	hs []*mat.Dense
+}
+
+func InitRNNLayer(w, b *mat.Dense, sequenceLength int, returnSequences bool) *RNNLayer {
+	return &RNNLayer{
+		W:               w,
+		B:               b,
+		sequenceLength:  sequenceLength,
+		returnSequences: returnSequences,
+	}
+}
+
+func (r *RNNLayer) GetDB() *mat.Dense {
+	return r.DB
+}
+
+func (r *RNNLayer) GetDW() *mat.Dense {
+	return r.DW
+}
+
+func (r *RNNLayer) Forward(x *mat.Dense) *mat.Dense {
+	batchSize, _ := x.Dims()
+	_, hiddenSize := r.W.Dims()
+	wx, wh := splitRecurrentWeight(r.W, hiddenSize)
+	inputSize, _ := wx.Dims()
+
+	r.xs = make([]*mat.Dense, r.sequenceLength)
+	r.hs = make([]*mat.Dense, r.sequenceLength+1)
+	r.hs[0] = mat.NewDense(batchSize, hiddenSize, nil)
+
+	out := mat.NewDense(batchSize, r.sequenceLength*hiddenSize, nil)
+	for t := 0; t < r.sequenceLength; t++ {
+		r.xs[t] = timeStep(x, t, inputSize)
+
+		h := mat.NewDense(batchSize, hiddenSize, nil)
+		tmp := mat.NewDense(batchSize, hiddenSize, nil)
+		h.Mul(r.xs[t], wx)
+		tmp.Mul(r.hs[t], wh)
+		h.Add(h, tmp)
+		for i := 0; i < batchSize; i++ {
+			for j := 0; j < hiddenSize; j++ {
+				h.Set(i, j, math.Tanh(h.At(i, j)+r.B.At(0, j)))
+			}
+		}
+
+		r.hs[t+1] = h
+		setTimeStep(out, t, h)
+	}
+
+	if !r.returnSequences {
+		return r.hs[r.sequenceLength]
+	}
+
+	return out
+}
+
+func (r *RNNLayer) Backward(dout *mat.Dense) *mat.Dense {
+	batchSize, _ := dout.Dims()
+	rw, hiddenSize := r.W.Dims()
+	wx, wh := splitRecurrentWeight(r.W, hiddenSize)
+	inputSize := rw - hiddenSize
+
+	r.DW = mat.NewDense(rw, hiddenSize, nil)
+	r.DB = mat.NewDense(1, hiddenSize, nil)
+	dwx, dwh := splitRecurrentWeight(r.DW, hiddenSize)
+
+	dx := mat.NewDense(batchSize, r.sequenceLength*inputSize, nil)
+	dhnext := mat.NewDense(batchSize, hiddenSize, nil)
+	for t := r.sequenceLength - 1; t >= 0; t-- {
+		dh := mat.DenseCopyOf(dhnext)
+		if r.returnSequences {
+			dh.Add(dh, timeStep(dout, t, hiddenSize))
+		} else if t == r.sequenceLength-1 {
+			dh.Add(dh, dout)
+		}
+
+		h := r.hs[t+1]
+		for i := 0; i < batchSize; i++ {
+			for j := 0; j < hiddenSize; j++ {
+				v := dh.At(i, j) * (1 - h.At(i, j)*h.At(i, j))
+				dh.Set(i, j, v)
+				r.DB.Set(0, j, r.DB.At(0, j)+v)
+			}
+		}
+
+		tmp := mat.NewDense(inputSize, hiddenSize, nil)
+		tmp.Mul(r.xs[t].T(), dh)
+		dwx.Add(dwx, tmp)
+		tmp = mat.NewDense(hiddenSize, hiddenSize, nil)
+		tmp.Mul(r.hs[t].T(), dh)
+		dwh.Add(dwh, tmp)
+
+		dxt := mat.NewDense(batchSize, inputSize, nil)
+		dxt.Mul(dh, wx.T())
+		setTimeStep(dx, t, dxt)
+		dhnext.Mul(dh, wh.T())
+	}
+
+	return dx
+}
@@ -0,0 +1,29 @@
+package layers
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// SequenceToBatchLayer turns every row holding sequenceLength steps into
+// sequenceLength rows of one step each, so that the layers after it, and
+// the loss, treat every step as a sample. Row n*sequenceLength+t of the
+// output is step t of row n of the input.
+type SequenceToBatchLayer struct {
+	sequenceLength int
+}
+
+func InitSequenceToBatchLayer(sequenceLength int) *SequenceToBatchLayer {
+	return &SequenceToBatchLayer{
+		sequenceLength: sequenceLength,
+	}
+}
+
+func (s *SequenceToBatchLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	return mat.NewDense(r*s.sequenceLength, c/s.sequenceLength, mat.DenseCopyOf(x).RawMatrix().Data)
+}
+
+func (s *SequenceToBatchLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	return mat.NewDense(r/s.sequenceLength, c*s.sequenceLength, mat.DenseCopyOf(dout).RawMatrix().Data)
+}
@@ -0,0 +1,14 @@
+package layers
+
+// Shape is the shape of one sample, such as (channel, height, width) for an
+// image or (sequenceLength, size) for a sequence. A sample is stored as one
+// row of a matrix in row-major order, so its row holds Size() values.
+type Shape []int
+
+func (s Shape) Size() int {
+	size := 1
+	for _, v := range s {
+		size = size * v
+	}
+	return size
+}
@@ -0,0 +1,72 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func sigmoid(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	ans := mat.NewDense(r, c, nil)
+
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			ans.Set(i, j, 1/(1+math.Exp(-1.0*x.At(i, j))))
+		}
+	}
+
+	return ans
+}
+
+// binaryCrossEntropyError takes the logits x rather than the probabilities,
+// which keeps it finite however saturated the sigmoid is.
+func binaryCrossEntropyError(x, t *mat.Dense) float64 {
+	batchSize, outputSize := x.Dims()
+
+	sum := 0.0
+
+	for i := 0; i < batchSize; i++ {
+		for j := 0; j < outputSize; j++ {
+			v := x.At(i, j)
+			sum = sum + math.Max(v, 0) - v*t.At(i, j) + math.Log1p(math.Exp(-math.Abs(v)))
+		}
+	}
+
+	return sum / float64(batchSize)
+}
+
+// SigmoidWithBCELossLayer treats every output as an independent binary
+// label, so a row of t may contain any number of 1s.
+type SigmoidWithBCELossLayer struct {
+	loss float64
+	y    *mat.Dense
+	t    *mat.Dense
+}
+
+func InitSigmoidWithBCELossLayer() *SigmoidWithBCELossLayer {
+	return &SigmoidWithBCELossLayer{}
+}
+
+func (s *SigmoidWithBCELossLayer) Forward(x, t *mat.Dense) float64 {
+	s.t = t
+	s.y = sigmoid(x)
+	s.loss = binaryCrossEntropyError(x, s.t)
+
+	return s.loss
+}
+
+func (s *SigmoidWithBCELossLayer) Backward(dout float64) *mat.Dense {
+	r, c := s.t.Dims()
+	dx := mat.NewDense(r, c, nil)
+
+	batchSize := float64(r)
+
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			dx.Set(i, j, dout*(s.y.At(i, j)-s.t.At(i, j))/batchSize)
+		}
+	}
+
+	return dx
+}
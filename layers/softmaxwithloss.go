@@ -1,6 +1,7 @@
 package layers
 
 import (
+	"errors"
 	"math"
 
 	"gonum.org/v1/gonum/mat"
@@ -26,7 +27,36 @@ func softmax(x *mat.Dense) *mat.Dense {
 	return ans
 }
 
-func crossEntropyError(x, t *mat.Dense) float64 {
+// smoothLabels mixes t with the uniform distribution, moving epsilon of the
+// probability mass of every row evenly over all the classes.
+func smoothLabels(t *mat.Dense, epsilon float64) *mat.Dense {
+	if epsilon == 0 {
+		return t
+	}
+
+	r, c := t.Dims()
+	ans := mat.NewDense(r, c, nil)
+
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			ans.Set(i, j, (1-epsilon)*t.At(i, j)+epsilon/float64(c))
+		}
+	}
+
+	return ans
+}
+
+func classWeight(weights []float64, j int) float64 {
+	if weights == nil {
+		return 1.0
+	}
+	return weights[j]
+}
+
+// crossEntropyError sums over every class, so that t may be a smoothed
+// distribution rather than one-hot. weights scales the term of each class
+// and may be nil.
+func crossEntropyError(x, t *mat.Dense, weights []float64) float64 {
 	delta := math.Pow10(-7)
 	batchSize, outputSize := x.Dims()
 
@@ -34,9 +64,8 @@ func crossEntropyError(x, t *mat.Dense) float64 {
 
 	for i := 0; i < batchSize; i++ {
 		for j := 0; j < outputSize; j++ {
-			if t.At(i, j) == 1.0 {
-				sum = sum + math.Log(x.At(i, j)+delta)
-				break
+			if t.At(i, j) != 0 {
+				sum = sum + classWeight(weights, j)*t.At(i, j)*math.Log(x.At(i, j)+delta)
 			}
 		}
 	}
@@ -45,6 +74,9 @@ func crossEntropyError(x, t *mat.Dense) float64 {
 }
 
 type SoftmaxWithLossLayer struct {
+	labelSmoothing float64
+	classWeights   []float64
+
 	loss float64
 	y    *mat.Dense
 	t    *mat.Dense
@@ -54,10 +86,36 @@ func InitSoftmaxWithLossLayer() *SoftmaxWithLossLayer {
 	return &SoftmaxWithLossLayer{}
 }
 
+// InitWeightedSoftmaxWithLossLayer returns a SoftmaxWithLossLayer for
+// classNum classes that smooths the labels with labelSmoothing, in [0, 1),
+// and weights the loss of each class with classWeights, which may be nil.
+// classWeights must have one non-negative weight per class.
+func InitWeightedSoftmaxWithLossLayer(classNum int, labelSmoothing float64, classWeights []float64) (*SoftmaxWithLossLayer, error) {
+	if classNum < 1 {
+		return nil, errors.New("Invalid Args: classNum is at least 1")
+	}
+	if labelSmoothing < 0 || labelSmoothing >= 1 {
+		return nil, errors.New("Invalid Args: labelSmoothing is at least 0 and less than 1")
+	}
+	if classWeights != nil && len(classWeights) != classNum {
+		return nil, errors.New("Invalid Args: the length of classWeights is classNum")
+	}
+	for _, w := range classWeights {
+		if w < 0 {
+			return nil, errors.New("Invalid Args: classWeights must not be negative")
+		}
+	}
+
+	return &SoftmaxWithLossLayer{
+		labelSmoothing: labelSmoothing,
+		classWeights:   classWeights,
+	}, nil
+}
+
 func (s *SoftmaxWithLossLayer) Forward(x, t *mat.Dense) float64 {
-	s.t = t
+	s.t = smoothLabels(t, s.labelSmoothing)
 	s.y = softmax(x)
-	s.loss = crossEntropyError(s.y, s.t)
+	s.loss = crossEntropyError(s.y, s.t, s.classWeights)
 
 	return s.loss
 }
@@ -69,8 +127,14 @@ func (s *SoftmaxWithLossLayer) Backward(dout float64) *mat.Dense {
 	batchSize := float64(r)
 
 	for i := 0; i < r; i++ {
+		// With weights the target mass of a row is no longer 1, so the
+		// softmax term is scaled by it.
+		mass := 0.0
+		for j := 0; j < c; j++ {
+			mass = mass + classWeight(s.classWeights, j)*s.t.At(i, j)
+		}
 		for j := 0; j < c; j++ {
-			dx.Set(i, j, (s.y.At(i, j)-s.t.At(i, j))/batchSize)
+			dx.Set(i, j, dout*(s.y.At(i, j)*mass-classWeight(s.classWeights, j)*s.t.At(i, j))/batchSize)
 		}
 	}
 
@@ -0,0 +1,40 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SoftplusLayer computes log(1+exp(x)), whose derivative is sigmoid(x).
+type SoftplusLayer struct {
+	x *mat.Dense
+}
+
+func InitSoftplusLayer() ActivationLayer {
+	return &SoftplusLayer{}
+}
+
+func (s *SoftplusLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := x.At(i, j)
+			out.Set(i, j, math.Max(v, 0)+math.Log1p(math.Exp(-math.Abs(v))))
+		}
+	}
+	s.x = mat.DenseCopyOf(x)
+	return out
+}
+
+func (s *SoftplusLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			dx.Set(i, j, dout.At(i, j)/(1+math.Exp(-1.0*s.x.At(i, j))))
+		}
+	}
+	return dx
+}
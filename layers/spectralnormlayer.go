@@ -0,0 +1,112 @@
+package layers
+
+import (
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SpectralNormLayer is an AffineLayer whose weight is divided by its largest
+// singular value, estimated as sigma = v^T W u. u and v approach the leading
+// singular vectors of W by power iteration, one step per Forward while
+// training, and are kept between calls. Backward treats them as constants.
+// A frozen layer keeps them as they are.
+// GetDW returns the gradient with respect to the unnormalized W.
+type SpectralNormLayer struct {
+	W  *mat.Dense
+	DW *mat.Dense
+
+	affine *AffineLayer
+	train  bool
+	freeze bool
+	u      *mat.VecDense
+	v      *mat.VecDense
+	sigma  float64
+}
+
+func InitSpectralNormLayer(w, b *mat.Dense) *SpectralNormLayer {
+	r, c := w.Dims()
+	u := make([]float64, c)
+	for i := range u {
+		u[i] = rand.NormFloat64()
+	}
+
+	s := &SpectralNormLayer{
+		W:      w,
+		affine: InitAffineLayer(mat.NewDense(r, c, nil), b),
+		u:      mat.NewVecDense(c, u),
+		v:      mat.NewVecDense(r, nil),
+	}
+	normalizeVec(s.u)
+	s.powerIteration()
+
+	return s
+}
+
+func (s *SpectralNormLayer) SetTrainFlag(train bool) {
+	s.train = train
+}
+
+func (s *SpectralNormLayer) SetFreezeFlag(freeze bool) {
+	s.freeze = freeze
+}
+
+func (s *SpectralNormLayer) GetDB() *mat.Dense {
+	return s.affine.GetDB()
+}
+
+func (s *SpectralNormLayer) GetDW() *mat.Dense {
+	return s.DW
+}
+
+func normalizeVec(x *mat.VecDense) {
+	norm := mat.Norm(x, 2)
+	if norm > 0 {
+		x.ScaleVec(1.0/norm, x)
+	}
+}
+
+// powerIteration moves v and u one step towards the leading left and right
+// singular vectors of W.
+func (s *SpectralNormLayer) powerIteration() {
+	s.v.MulVec(s.W, s.u)
+	normalizeVec(s.v)
+	s.u.MulVec(s.W.T(), s.v)
+	normalizeVec(s.u)
+}
+
+func (s *SpectralNormLayer) Forward(x *mat.Dense) *mat.Dense {
+	if s.train && !s.freeze {
+		s.powerIteration()
+	}
+
+	wu := mat.NewVecDense(s.v.Len(), nil)
+	wu.MulVec(s.W, s.u)
+	s.sigma = mat.Dot(s.v, wu)
+	s.affine.W.Scale(1.0/s.sigma, s.W)
+
+	return s.affine.Forward(x)
+}
+
+func (s *SpectralNormLayer) Backward(dout *mat.Dense) *mat.Dense {
+	dx := s.affine.Backward(dout)
+	dw := s.affine.GetDW()
+
+	// dsigma/dW = v u^T, so dL/dW = (dL/dWsn - <dL/dWsn, Wsn> v u^T) / sigma.
+	r, c := s.W.Dims()
+	inner := 0.0
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			inner = inner + dw.At(i, j)*s.affine.W.At(i, j)
+		}
+	}
+
+	s.DW = mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			s.DW.Set(i, j, (dw.At(i, j)-inner*s.v.AtVec(i)*s.u.AtVec(j))/s.sigma)
+		}
+	}
+
+	return dx
+}
@@ -0,0 +1,46 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// SwishLayer computes x*sigmoid(x), also known as SiLU.
+type SwishLayer struct {
+	x   *mat.Dense
+	sig *mat.Dense
+}
+
+func InitSwishLayer() ActivationLayer {
+	return &SwishLayer{}
+}
+
+func (s *SwishLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	s.sig = mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := x.At(i, j)
+			sig := 1 / (1 + math.Exp(-1.0*v))
+			s.sig.Set(i, j, sig)
+			out.Set(i, j, v*sig)
+		}
+	}
+	s.x = mat.DenseCopyOf(x)
+	return out
+}
+
+func (s *SwishLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			v := s.x.At(i, j)
+			sig := s.sig.At(i, j)
+			dx.Set(i, j, dout.At(i, j)*(sig+v*sig*(1-sig)))
+		}
+	}
+	return dx
+}
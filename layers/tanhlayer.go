@@ -0,0 +1,39 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+type TanhLayer struct {
+	out *mat.Dense
+}
+
+func InitTanhLayer() ActivationLayer {
+	return &TanhLayer{}
+}
+
+func (t *TanhLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := x.Dims()
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			out.Set(i, j, math.Tanh(x.At(i, j)))
+		}
+	}
+	t.out = mat.DenseCopyOf(out)
+	return out
+}
+
+func (t *TanhLayer) Backward(dout *mat.Dense) *mat.Dense {
+	r, c := dout.Dims()
+	dx := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			y := t.out.At(i, j)
+			dx.Set(i, j, dout.At(i, j)*(1-y*y))
+		}
+	}
+	return dx
+}
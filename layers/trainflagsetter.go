@@ -0,0 +1,15 @@
+package layers
+
+// TrainFlagSetter is implemented by layers that behave differently while
+// training and at inference.
+type TrainFlagSetter interface {
+	SetTrainFlag(train bool)
+}
+
+// FreezeFlagSetter is implemented by layers that update some state of their
+// own while training. A frozen layer still behaves as in training but leaves
+// that state as it is, so that evaluating the loss, for example to check
+// gradients numerically, has no side effects.
+type FreezeFlagSetter interface {
+	SetFreezeFlag(freeze bool)
+}
@@ -0,0 +1,123 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const (
+	UpsamplingAlgorismNearest = iota
+	UpsamplingAlgorismBilinear
+)
+
+type UpsamplingAlgorism int
+
+// UpsamplingLayer enlarges channel-major images stored one per row by an
+// integer scale, copying the nearest pixel or interpolating bilinearly
+// between the pixels around the centre of every output pixel.
+type UpsamplingLayer struct {
+	channel int
+	height  int
+	width   int
+	scale   int
+
+	// Output row (or column) i reads the input rows (or columns) lo[i] and
+	// hi[i], weighted 1-frac[i] and frac[i].
+	rowLo, rowHi []int
+	rowFrac      []float64
+	colLo, colHi []int
+	colFrac      []float64
+}
+
+func upsamplingWeights(size, scale int, a UpsamplingAlgorism) (lo, hi []int, frac []float64) {
+	lo = make([]int, size*scale)
+	hi = make([]int, size*scale)
+	frac = make([]float64, size*scale)
+
+	for i := 0; i < size*scale; i++ {
+		if a != UpsamplingAlgorismBilinear {
+			lo[i] = i / scale
+			hi[i] = lo[i]
+			continue
+		}
+
+		src := (float64(i)+0.5)/float64(scale) - 0.5
+		src = math.Max(0, math.Min(float64(size-1), src))
+		lo[i] = int(math.Floor(src))
+		hi[i] = lo[i]
+		if lo[i] < size-1 {
+			hi[i] = lo[i] + 1
+		}
+		frac[i] = src - float64(lo[i])
+	}
+
+	return
+}
+
+func InitUpsamplingLayer(channel, height, width, scale int, a UpsamplingAlgorism) *UpsamplingLayer {
+	u := &UpsamplingLayer{
+		channel: channel,
+		height:  height,
+		width:   width,
+		scale:   scale,
+	}
+	u.rowLo, u.rowHi, u.rowFrac = upsamplingWeights(height, scale, a)
+	u.colLo, u.colHi, u.colFrac = upsamplingWeights(width, scale, a)
+
+	return u
+}
+
+func (u *UpsamplingLayer) OutputShape() (channel, height, width int) {
+	return u.channel, u.height * u.scale, u.width * u.scale
+}
+
+func (u *UpsamplingLayer) Forward(x *mat.Dense) *mat.Dense {
+	n, _ := x.Dims()
+	_, oh, ow := u.OutputShape()
+	out := mat.NewDense(n, u.channel*oh*ow, nil)
+
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < u.channel; ch++ {
+			in := ch * u.height * u.width
+			for y := 0; y < oh; y++ {
+				for xx := 0; xx < ow; xx++ {
+					top := (1-u.colFrac[xx])*x.At(i, in+u.rowLo[y]*u.width+u.colLo[xx]) + u.colFrac[xx]*x.At(i, in+u.rowLo[y]*u.width+u.colHi[xx])
+					bottom := (1-u.colFrac[xx])*x.At(i, in+u.rowHi[y]*u.width+u.colLo[xx]) + u.colFrac[xx]*x.At(i, in+u.rowHi[y]*u.width+u.colHi[xx])
+					out.Set(i, (ch*oh+y)*ow+xx, (1-u.rowFrac[y])*top+u.rowFrac[y]*bottom)
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+func (u *UpsamplingLayer) Backward(dout *mat.Dense) *mat.Dense {
+	n, _ := dout.Dims()
+	_, oh, ow := u.OutputShape()
+	dx := mat.NewDense(n, u.channel*u.height*u.width, nil)
+
+	add := func(i, j int, v float64) {
+		dx.Set(i, j, dx.At(i, j)+v)
+	}
+
+	for i := 0; i < n; i++ {
+		for ch := 0; ch < u.channel; ch++ {
+			in := ch * u.height * u.width
+			for y := 0; y < oh; y++ {
+				for xx := 0; xx < ow; xx++ {
+					d := dout.At(i, (ch*oh+y)*ow+xx)
+					top := (1 - u.rowFrac[y]) * d
+					bottom := u.rowFrac[y] * d
+					add(i, in+u.rowLo[y]*u.width+u.colLo[xx], (1-u.colFrac[xx])*top)
+					add(i, in+u.rowLo[y]*u.width+u.colHi[xx], u.colFrac[xx]*top)
+					add(i, in+u.rowHi[y]*u.width+u.colLo[xx], (1-u.colFrac[xx])*bottom)
+					add(i, in+u.rowHi[y]*u.width+u.colHi[xx], u.colFrac[xx]*bottom)
+				}
+			}
+		}
+	}
+
+	return dx
+}
@@ -0,0 +1,91 @@
+package layers
+
+import (
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// WeightNormLayer is an AffineLayer whose weight is reparameterized as
+// W = g * V / ||V||, column by column, so that the direction V and the
+// magnitude g of every output unit are learned separately. GetDW returns the
+// gradient with respect to V.
+type WeightNormLayer struct {
+	V  *mat.Dense
+	G  []float64
+	DV *mat.Dense
+	DG []float64
+
+	affine *AffineLayer
+	norm   []float64
+}
+
+func InitWeightNormLayer(v, b *mat.Dense, g []float64) *WeightNormLayer {
+	r, c := v.Dims()
+	return &WeightNormLayer{
+		V:      v,
+		G:      g,
+		affine: InitAffineLayer(mat.NewDense(r, c, nil), b),
+	}
+}
+
+// ColumnNorms returns the Euclidean norm of every column of v, which makes
+// a WeightNormLayer compute the same function as an AffineLayer of weight v
+// when used as g.
+func ColumnNorms(v *mat.Dense) []float64 {
+	r, c := v.Dims()
+	norm := make([]float64, c)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			norm[j] = norm[j] + v.At(i, j)*v.At(i, j)
+		}
+		norm[j] = math.Sqrt(norm[j])
+	}
+
+	return norm
+}
+
+func (w *WeightNormLayer) GetDB() *mat.Dense {
+	return w.affine.GetDB()
+}
+
+func (w *WeightNormLayer) GetDW() *mat.Dense {
+	return w.DV
+}
+
+func (w *WeightNormLayer) GetDG() []float64 {
+	return w.DG
+}
+
+func (w *WeightNormLayer) Forward(x *mat.Dense) *mat.Dense {
+	r, c := w.V.Dims()
+	w.norm = ColumnNorms(w.V)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			w.affine.W.Set(i, j, w.G[j]*w.V.At(i, j)/w.norm[j])
+		}
+	}
+
+	return w.affine.Forward(x)
+}
+
+func (w *WeightNormLayer) Backward(dout *mat.Dense) *mat.Dense {
+	dx := w.affine.Backward(dout)
+	dw := w.affine.GetDW()
+
+	r, c := w.V.Dims()
+	w.DV = mat.NewDense(r, c, nil)
+	w.DG = make([]float64, c)
+	for j := 0; j < c; j++ {
+		for i := 0; i < r; i++ {
+			w.DG[j] = w.DG[j] + dw.At(i, j)*w.V.At(i, j)
+		}
+		w.DG[j] = w.DG[j] / w.norm[j]
+
+		for i := 0; i < r; i++ {
+			w.DV.Set(i, j, w.G[j]/w.norm[j]*(dw.At(i, j)-w.DG[j]*w.V.At(i, j)/w.norm[j]))
+		}
+	}
+
+	return dx
+}
@@ -56,8 +56,10 @@ func main() {
 	net, err := neuralnetwork.InitMultiLayerNet(
 		neurons,
 		0.01,
-		neuralnetwork.ActivationAlgorismReLu,
-		neuralnetwork.NormalizationAlgorismNo)
+		neuralnetwork.DefaultActivationConfig(neuralnetwork.ActivationAlgorismReLu),
+		neuralnetwork.NormalizationAlgorismNo,
+		0.0,
+		neuralnetwork.OutputAlgorismSoftmaxWithLoss)
 	if err != nil {
 		fmt.Println(err.Error())
 		return
@@ -10,27 +10,203 @@ import (
 const (
 	ActivationAlgorismSigmoid = iota
 	ActivationAlgorismReLu
+	ActivationAlgorismTanh
+	ActivationAlgorismLeakyReLu
+	ActivationAlgorismElu
+	ActivationAlgorismGeLu
+	ActivationAlgorismSwish
+	ActivationAlgorismSoftplus
+	ActivationAlgorismPReLu
+	ActivationAlgorismMaxout
 )
 
 const (
 	NormalizationAlgorismNo = iota
 	NormalizationAlgorismBatchNorm
+	NormalizationAlgorismLayerNorm
+	NormalizationAlgorismGroupNorm
+	NormalizationAlgorismInstanceNorm
+)
+
+const (
+	OutputAlgorismSoftmaxWithLoss = iota
+	OutputAlgorismMeanSquaredError
+	OutputAlgorismMeanAbsoluteError
+	OutputAlgorismHuberLoss
+	OutputAlgorismSigmoidWithBCELoss
+)
+
+const (
+	RecurrentAlgorismRNN = iota
+	RecurrentAlgorismLSTM
+	RecurrentAlgorismGRU
+)
+
+const (
+	ReparameterizationAlgorismNo = iota
+	ReparameterizationAlgorismWeightNorm
+	ReparameterizationAlgorismSpectralNorm
 )
 
 type ActivationAlgorism int
 type NormalizationAlgorism int
+type OutputAlgorism int
+type RecurrentAlgorism int
+type ReparameterizationAlgorism int
 
 type MultiLayerNet struct {
 	params              *Params
 	affineLayers        []layers.Layer
 	activationLayers    []layers.ActivationLayer
 	normalizationLayers []layers.NormalizationLayer
+	dropoutLayers       []layers.ActivationLayer
+	residualLayers      []*layers.AddLayer
+	skipFrom            []int
+	channels            []int
+	groups              []int
 	lastLayer           layers.OutputLayer
+	output              OutputAlgorism
 	neurons             []int
 	depth               int
+	train               bool
+}
+
+// maxoutPieces is the number of affine pieces of every MaxoutLayer built
+// for ActivationAlgorismMaxout.
+const maxoutPieces = 2
+
+// ActivationConfig chooses the activation of the hidden layers. Slope is
+// the slope of LeakyReLU for negative inputs and Alpha the alpha of ELU;
+// the other activations ignore them.
+type ActivationConfig struct {
+	Algorism ActivationAlgorism
+	Slope    float64
+	Alpha    float64
+}
+
+// DefaultActivationConfig returns the ActivationConfig of a with a slope of
+// 0.01 for LeakyReLU and an alpha of 1.0 for ELU.
+func DefaultActivationConfig(a ActivationAlgorism) ActivationConfig {
+	return ActivationConfig{
+		Algorism: a,
+		Slope:    0.01,
+		Alpha:    1.0,
+	}
+}
+
+// ConvParam describes one convolution layer. Groups is the number of groups
+// of NormalizationAlgorismGroupNorm after the layer and must divide
+// FilterNum; 0 chooses the greatest common divisor of FilterNum and 32.
+type ConvParam struct {
+	FilterNum  int
+	FilterSize int
+	Stride     int
+	Pad        int
+	Groups     int
+}
+
+func activationLayerInitializer(a ActivationConfig) func() layers.ActivationLayer {
+	switch a.Algorism {
+	case ActivationAlgorismSigmoid:
+		return layers.InitSigmoidLayer
+	case ActivationAlgorismReLu:
+		return layers.InitReLuLayer
+	case ActivationAlgorismTanh:
+		return layers.InitTanhLayer
+	case ActivationAlgorismLeakyReLu:
+		return func() layers.ActivationLayer {
+			return layers.InitLeakyReLuLayer(a.Slope)
+		}
+	case ActivationAlgorismElu:
+		return func() layers.ActivationLayer {
+			return layers.InitEluLayer(a.Alpha)
+		}
+	case ActivationAlgorismGeLu:
+		return layers.InitGeLuLayer
+	case ActivationAlgorismSwish:
+		return layers.InitSwishLayer
+	case ActivationAlgorismSoftplus:
+		return layers.InitSoftplusLayer
+	}
+
+	return layers.InitSigmoidLayer
+}
+
+func normalizationLayerInitializer(n NormalizationAlgorism) func(g, b []float64) layers.NormalizationLayer {
+	switch n {
+	case NormalizationAlgorismBatchNorm:
+		return layers.InitBatchNormLayer
+	case NormalizationAlgorismLayerNorm:
+		return layers.InitLayerNormLayer
+	case NormalizationAlgorismNo:
+		return layers.InitNoNormalizationLayer
+	}
+
+	return layers.InitNoNormalizationLayer
 }
 
-func InitMultiLayerNet(neurons []int, weightInitStd float64, a ActivationAlgorism, n NormalizationAlgorism) (NeuralNetwork, error) {
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// hasConvLayers reports whether the network has convolution layers.
+func (m *MultiLayerNet) hasConvLayers() bool {
+	for _, c := range m.channels {
+		if c > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// initNormalizationLayer creates the normalization layer of depth d and its
+// Gamma and Beta. Group and instance normalization work on the channels of
+// convolution layers, with one Gamma and Beta per channel, and the groups of
+// the ConvParam for group normalization. The fully connected layers of a
+// convolutional network are left without normalization, and a network
+// without convolution layers cannot use them.
+func (m *MultiLayerNet) initNormalizationLayer(d int, n NormalizationAlgorism) error {
+	size := m.neurons[d+1]
+	channel := m.channels[d]
+
+	if n != NormalizationAlgorismGroupNorm && n != NormalizationAlgorismInstanceNorm {
+		m.params.Gamma[d] = makeSliceFloat64(size, 1.0)
+		m.params.Beta[d] = makeSliceFloat64(size, 0.0)
+		m.normalizationLayers[d] = normalizationLayerInitializer(n)(m.params.Gamma[d], m.params.Beta[d])
+		return nil
+	}
+
+	if !m.hasConvLayers() {
+		return errors.New("Invalid Args: GroupNorm and InstanceNorm need convolution layers")
+	}
+
+	if channel == 0 {
+		m.normalizationLayers[d] = layers.InitNoNormalizationLayer(nil, nil)
+		return nil
+	}
+
+	m.params.Gamma[d] = makeSliceFloat64(channel, 1.0)
+	m.params.Beta[d] = makeSliceFloat64(channel, 0.0)
+	if n == NormalizationAlgorismInstanceNorm {
+		m.normalizationLayers[d] = layers.InitInstanceNormLayer(m.params.Gamma[d], m.params.Beta[d], size/channel)
+		return nil
+	}
+
+	l, err := layers.InitGroupNormLayer(m.params.Gamma[d], m.params.Beta[d], m.groups[d], size/channel)
+	if err != nil {
+		return err
+	}
+	m.normalizationLayers[d] = l
+	return nil
+}
+
+// InitMultiLayerNet builds a fully connected network. When dropoutRatio is
+// greater than 0, a DropoutLayer follows every hidden layer. o chooses the
+// loss the network is trained with.
+func InitMultiLayerNet(neurons []int, weightInitStd float64, a ActivationConfig, n NormalizationAlgorism, dropoutRatio float64, o OutputAlgorism) (NeuralNetwork, error) {
 
 	depth := len(neurons) - 1
 
@@ -38,80 +214,445 @@ func InitMultiLayerNet(neurons []int, weightInitStd float64, a ActivationAlgoris
 		return nil, errors.New("Invalid Args: the length of neurons is at least 2")
 	}
 
-	m := MultiLayerNet{
-		params:  InitParams(depth),
-		neurons: neurons,
-		depth:   depth,
+	m := newMultiLayerNet(depth, neurons)
+	m.initAffineLayers(0, neurons, weightInitStd, a)
+	if err := m.initActivationAndNormalizationLayers(0, a, n); err != nil {
+		return nil, err
+	}
+	if err := m.initDropoutLayers(dropoutRatio); err != nil {
+		return nil, err
+	}
+	m.initLastLayer(o)
+
+	return m, nil
+}
+
+// InitMultiLayerConvNet builds a network whose first layers are 2D
+// convolutions over channel x height x width images, followed by affine
+// layers of the given sizes. neurons does not include the input size, which
+// is derived from the output of the last convolution.
+func InitMultiLayerConvNet(channel, height, width int, convs []ConvParam, neurons []int, weightInitStd float64, a ActivationConfig, n NormalizationAlgorism, dropoutRatio float64, o OutputAlgorism) (NeuralNetwork, error) {
+
+	if len(neurons) < 1 {
+		return nil, errors.New("Invalid Args: the length of neurons is at least 1")
 	}
 
-	m.affineLayers = make([]layers.Layer, m.depth)
-	m.activationLayers = make([]layers.ActivationLayer, m.depth)
-	m.normalizationLayers = make([]layers.NormalizationLayer, m.depth)
+	depth := len(convs) + len(neurons)
+	m := newMultiLayerNet(depth, []int{channel * height * width})
 
-	initActivationLayer := layers.InitSigmoidLayer
-	switch a {
-	case ActivationAlgorismSigmoid:
-		initActivationLayer = layers.InitSigmoidLayer
-	case ActivationAlgorismReLu:
-		initActivationLayer = layers.InitReLuLayer
+	for d, cp := range convs {
+		if cp.FilterNum < 1 || cp.FilterSize < 1 || cp.Stride < 1 || cp.Pad < 0 || cp.Groups < 0 {
+			return nil, errors.New("Invalid Args: invalid convolution parameter")
+		}
+
+		w := makeRandSliceFloat64(channel*cp.FilterSize*cp.FilterSize*cp.FilterNum, weightInitStd)
+		b := makeRandSliceFloat64(cp.FilterNum, weightInitStd)
+
+		weight := mat.NewDense(channel*cp.FilterSize*cp.FilterSize, cp.FilterNum, w)
+		bias := mat.NewDense(1, cp.FilterNum, b)
+
+		conv := layers.InitConv2DLayer(weight, bias, channel, height, width, cp.FilterSize, cp.FilterSize, cp.Stride, cp.Pad)
+		channel, height, width = conv.OutputShape()
+		if height < 1 || width < 1 {
+			return nil, errors.New("Invalid Args: the image is smaller than the filter")
+		}
+
+		m.params.Weight[d] = weight
+		m.params.Bias[d] = bias
+		m.affineLayers[d] = conv
+		m.channels[d] = channel
+		m.groups[d] = cp.Groups
+		if m.groups[d] == 0 {
+			m.groups[d] = gcd(channel, 32)
+		}
+		m.neurons = append(m.neurons, channel*height*width)
+	}
+
+	m.neurons = append(m.neurons, neurons...)
+
+	m.initAffineLayers(len(convs), m.neurons, weightInitStd, a)
+	if err := m.initActivationAndNormalizationLayers(0, a, n); err != nil {
+		return nil, err
+	}
+	if err := m.initDropoutLayers(dropoutRatio); err != nil {
+		return nil, err
+	}
+	m.initLastLayer(o)
+
+	return m, nil
+}
+
+// InitMultiLayerEmbeddingNet builds a network whose input rows hold
+// sequenceLength integer ids below vocabularySize. The first layer is an
+// EmbeddingLayer of embeddingSize, whose table is the Weight of depth 0 and
+// which has no Bias, followed by affine layers of the given sizes.
+func InitMultiLayerEmbeddingNet(vocabularySize, sequenceLength, embeddingSize int, neurons []int, weightInitStd float64, a ActivationConfig, n NormalizationAlgorism, dropoutRatio float64, o OutputAlgorism) (NeuralNetwork, error) {
+
+	if len(neurons) < 1 {
+		return nil, errors.New("Invalid Args: the length of neurons is at least 1")
+	}
+	if vocabularySize < 1 || sequenceLength < 1 || embeddingSize < 1 {
+		return nil, errors.New("Invalid Args: invalid embedding parameter")
+	}
+
+	depth := 1 + len(neurons)
+	m := newMultiLayerNet(depth, []int{sequenceLength, sequenceLength * embeddingSize})
+
+	w := makeRandSliceFloat64(vocabularySize*embeddingSize, weightInitStd)
+	weight := mat.NewDense(vocabularySize, embeddingSize, w)
+
+	m.params.Weight[0] = weight
+	m.affineLayers[0] = layers.InitEmbeddingLayer(weight)
+	m.neurons = append(m.neurons, neurons...)
+
+	m.initAffineLayers(1, m.neurons, weightInitStd, a)
+	if err := m.initActivationAndNormalizationLayers(1, a, n); err != nil {
+		return nil, err
+	}
+	if err := m.initDropoutLayers(dropoutRatio); err != nil {
+		return nil, err
+	}
+	m.initLastLayer(o)
+
+	return m, nil
+}
+
+// InitMultiLayerRecurrentNet builds a network whose input rows hold
+// sequenceLength steps of inputSize values. The first layer is a recurrent
+// layer of hiddenSize, whose stacked input and recurrent weights and gates
+// are the Weight and Bias of depth 0, followed by affine layers of the given
+// sizes. The affine layers see the hidden state of the last step, or, when
+// everyTimeStep is true, that of every step as a row of its own, in which
+// case the targets hold one row per step in the same order.
+func InitMultiLayerRecurrentNet(r RecurrentAlgorism, sequenceLength, inputSize, hiddenSize int, everyTimeStep bool, neurons []int, weightInitStd float64, a ActivationConfig, n NormalizationAlgorism, dropoutRatio float64, o OutputAlgorism) (NeuralNetwork, error) {
+
+	if len(neurons) < 1 {
+		return nil, errors.New("Invalid Args: the length of neurons is at least 1")
+	}
+	if sequenceLength < 1 || inputSize < 1 || hiddenSize < 1 {
+		return nil, errors.New("Invalid Args: invalid recurrent parameter")
+	}
+
+	depth := 1 + len(neurons)
+	m := newMultiLayerNet(depth, []int{sequenceLength * inputSize, hiddenSize})
+
+	gates := 1
+	switch r {
+	case RecurrentAlgorismLSTM:
+		gates = 4
+	case RecurrentAlgorismGRU:
+		gates = 3
+	}
+
+	w := makeRandSliceFloat64((inputSize+hiddenSize)*gates*hiddenSize, weightInitStd)
+	b := makeRandSliceFloat64(gates*hiddenSize, weightInitStd)
+	weight := mat.NewDense(inputSize+hiddenSize, gates*hiddenSize, w)
+	bias := mat.NewDense(1, gates*hiddenSize, b)
+
+	m.params.Weight[0] = weight
+	m.params.Bias[0] = bias
+	switch r {
+	case RecurrentAlgorismRNN:
+		m.affineLayers[0] = layers.InitRNNLayer(weight, bias, sequenceLength, everyTimeStep)
+	case RecurrentAlgorismLSTM:
+		m.affineLayers[0] = layers.InitLSTMLayer(weight, bias, sequenceLength, everyTimeStep)
+	case RecurrentAlgorismGRU:
+		m.affineLayers[0] = layers.InitGRULayer(weight, bias, sequenceLength, everyTimeStep)
 	default:
-		initActivationLayer = layers.InitSigmoidLayer
+		return nil, errors.New("Invalid Args: unknown recurrent algorism")
 	}
+	m.neurons = append(m.neurons, neurons...)
 
-	initNormalizationLayer := layers.InitNoNormalizationLayer
-	switch n {
-	case NormalizationAlgorismBatchNorm:
-		initNormalizationLayer = layers.InitBatchNormLayer
-	case NormalizationAlgorismNo:
-		initNormalizationLayer = layers.InitNoNormalizationLayer
+	m.initAffineLayers(1, m.neurons, weightInitStd, a)
+	if err := m.initActivationAndNormalizationLayers(1, a, n); err != nil {
+		return nil, err
+	}
+	if everyTimeStep {
+		m.activationLayers[0] = layers.InitSequenceToBatchLayer(sequenceLength)
+	}
+	if err := m.initDropoutLayers(dropoutRatio); err != nil {
+		return nil, err
 	}
+	m.initLastLayer(o)
 
-	for d := 0; d < depth; d++ {
-		w := makeRandSliceFloat64(neurons[d]*neurons[d+1], weightInitStd)
-		b := makeRandSliceFloat64(neurons[d+1], weightInitStd)
+	return m, nil
+}
+
+// InitMultiLayerResidualNet builds a ResNet-style fully connected network.
+// An affine layer maps inputSize to width, blocks residual blocks of
+// blockDepth layers of width follow, and a last affine layer maps width to
+// outputSize. The input of every block is added to its output through an
+// AddLayer.
+func InitMultiLayerResidualNet(inputSize, width, blocks, blockDepth, outputSize int, weightInitStd float64, a ActivationConfig, n NormalizationAlgorism, dropoutRatio float64, o OutputAlgorism) (NeuralNetwork, error) {
 
-		weight := mat.NewDense(neurons[d], neurons[d+1], w)
-		bias := mat.NewDense(1, neurons[d+1], b)
+	if inputSize < 1 || width < 1 || outputSize < 1 || blocks < 0 || blockDepth < 1 {
+		return nil, errors.New("Invalid Args: invalid residual network parameter")
+	}
+
+	neurons := []int{inputSize}
+	for d := 0; d <= blocks*blockDepth; d++ {
+		neurons = append(neurons, width)
+	}
+	neurons = append(neurons, outputSize)
+
+	depth := len(neurons) - 1
+	m := newMultiLayerNet(depth, neurons)
+	m.initAffineLayers(0, neurons, weightInitStd, a)
+	if err := m.initActivationAndNormalizationLayers(0, a, n); err != nil {
+		return nil, err
+	}
+	if err := m.initDropoutLayers(dropoutRatio); err != nil {
+		return nil, err
+	}
+	m.initLastLayer(o)
+
+	for b := 0; b < blocks; b++ {
+		start := 1 + b*blockDepth
+		end := start + blockDepth - 1
+		m.residualLayers[end] = layers.InitAddLayer()
+		m.skipFrom[end] = start
+	}
+
+	return m, nil
+}
+
+func newMultiLayerNet(depth int, neurons []int) *MultiLayerNet {
+	return &MultiLayerNet{
+		params:              InitParams(depth),
+		affineLayers:        make([]layers.Layer, depth),
+		activationLayers:    make([]layers.ActivationLayer, depth),
+		normalizationLayers: make([]layers.NormalizationLayer, depth),
+		dropoutLayers:       make([]layers.ActivationLayer, depth),
+		residualLayers:      make([]*layers.AddLayer, depth),
+		skipFrom:            make([]int, depth),
+		channels:            make([]int, depth),
+		groups:              make([]int, depth),
+		neurons:             neurons,
+		depth:               depth,
+	}
+}
+
+// initAffineLayers creates the affine layers from depth start onwards.
+// neurons[d] and neurons[d+1] are the input and output sizes of layer d.
+// With ActivationAlgorismMaxout the hidden layers are MaxoutLayers, whose
+// pieces are side by side in Weight and Bias.
+func (m *MultiLayerNet) initAffineLayers(start int, neurons []int, weightInitStd float64, a ActivationConfig) {
+	for d := start; d < m.depth; d++ {
+		pieces := 1
+		if a.Algorism == ActivationAlgorismMaxout && d < m.depth-1 {
+			pieces = maxoutPieces
+		}
+
+		w := makeRandSliceFloat64(neurons[d]*pieces*neurons[d+1], weightInitStd)
+		b := makeRandSliceFloat64(pieces*neurons[d+1], weightInitStd)
+
+		weight := mat.NewDense(neurons[d], pieces*neurons[d+1], w)
+		bias := mat.NewDense(1, pieces*neurons[d+1], b)
 
 		m.params.Weight[d] = weight
 		m.params.Bias[d] = bias
-		m.params.Beta[d] = makeSliceFloat64(neurons[d+1], 0.0)
-		m.params.Gamma[d] = makeSliceFloat64(neurons[d+1], 1.0)
+		if pieces > 1 {
+			m.affineLayers[d] = layers.InitMaxoutLayer(weight, bias, pieces)
+		} else {
+			m.affineLayers[d] = layers.InitAffineLayer(weight, bias)
+		}
+	}
+}
+
+// initActivationAndNormalizationLayers leaves the layers below depth start
+// without activation and normalization.
+func (m *MultiLayerNet) initActivationAndNormalizationLayers(start int, a ActivationConfig, n NormalizationAlgorism) error {
+	initActivationLayer := activationLayerInitializer(a)
 
-		m.affineLayers[d] = layers.InitAffineLayer(weight, bias)
-		m.normalizationLayers[d] = initNormalizationLayer(m.params.Beta[d], m.params.Gamma[d])
+	for d := 0; d < start; d++ {
+		m.normalizationLayers[d] = layers.InitNoNormalizationLayer(nil, nil)
+		m.activationLayers[d] = layers.InitIdentityLayer()
+	}
+
+	for d := start; d < m.depth; d++ {
+		if err := m.initNormalizationLayer(d, n); err != nil {
+			return err
+		}
 
-		if d < depth-1 {
-			m.activationLayers[d] = initActivationLayer()
+		if d < m.depth-1 {
+			m.initActivationLayer(d, a, initActivationLayer)
 		} else {
 			m.activationLayers[d] = layers.InitIdentityLayer()
 		}
 	}
 
-	m.lastLayer = layers.InitSoftmaxWithLossLayer()
+	return nil
+}
 
-	return &m, nil
+// initActivationLayer creates the activation layer of hidden depth d. PReLU
+// has one slope per channel on convolution layers and one per feature on
+// fully connected layers, starting at 0.25, kept as the Alpha of depth d.
+// A MaxoutLayer is its own activation, and convolution layers, which cannot
+// be Maxout, fall back to ReLU.
+func (m *MultiLayerNet) initActivationLayer(d int, a ActivationConfig, initActivationLayer func() layers.ActivationLayer) {
+	switch a.Algorism {
+	case ActivationAlgorismPReLu:
+		size := m.neurons[d+1]
+		channel := m.channels[d]
+		if channel == 0 {
+			channel = size
+		}
+		m.params.Alpha[d] = makeSliceFloat64(channel, 0.25)
+		m.activationLayers[d] = layers.InitPReLuLayer(m.params.Alpha[d], size/channel)
+	case ActivationAlgorismMaxout:
+		if _, ok := m.affineLayers[d].(*layers.MaxoutLayer); ok {
+			m.activationLayers[d] = layers.InitIdentityLayer()
+		} else {
+			m.activationLayers[d] = layers.InitReLuLayer()
+		}
+	default:
+		m.activationLayers[d] = initActivationLayer()
+	}
 }
 
-func (m *MultiLayerNet) Predict(x *mat.Dense) *mat.Dense {
+func (m *MultiLayerNet) initDropoutLayers(ratio float64) error {
+	if ratio < 0 || ratio >= 1 {
+		return errors.New("Invalid Args: dropoutRatio is at least 0 and less than 1")
+	}
+
+	for d := 0; d < m.depth; d++ {
+		if ratio > 0 && d < m.depth-1 {
+			m.dropoutLayers[d] = layers.InitDropoutLayer(ratio)
+		} else {
+			m.dropoutLayers[d] = layers.InitIdentityLayer()
+		}
+	}
+
+	return nil
+}
+
+func (m *MultiLayerNet) initLastLayer(o OutputAlgorism) {
+	m.output = o
+
+	switch o {
+	case OutputAlgorismMeanSquaredError:
+		m.lastLayer = layers.InitMeanSquaredErrorLayer()
+	case OutputAlgorismMeanAbsoluteError:
+		m.lastLayer = layers.InitMeanAbsoluteErrorLayer()
+	case OutputAlgorismHuberLoss:
+		m.lastLayer = layers.InitHuberLossLayer(1.0)
+	case OutputAlgorismSigmoidWithBCELoss:
+		m.lastLayer = layers.InitSigmoidWithBCELossLayer()
+	default:
+		m.output = OutputAlgorismSoftmaxWithLoss
+		m.lastLayer = layers.InitSoftmaxWithLossLayer()
+	}
+}
+
+// SetLastLayer replaces the loss layer chosen by the OutputAlgorism, for
+// example with a SoftmaxWithLossLayer that uses label smoothing or class
+// weights. Accuracy still follows the OutputAlgorism, so l must compute the
+// same kind of loss.
+func (m *MultiLayerNet) SetLastLayer(l layers.OutputLayer) {
+	m.lastLayer = l
+}
+
+// SetReparameterization replaces every AffineLayer of the network by a
+// WeightNormLayer or a SpectralNormLayer over the same Weight and Bias. The
+// magnitudes of weight normalization are the Magnitude of their depth and
+// start at the column norms of Weight, so the network computes the same
+// function as before.
+func (m *MultiLayerNet) SetReparameterization(r ReparameterizationAlgorism) {
+	for d := 0; d < m.depth; d++ {
+		if _, ok := m.affineLayers[d].(*layers.AffineLayer); !ok {
+			continue
+		}
+
+		switch r {
+		case ReparameterizationAlgorismWeightNorm:
+			m.params.Magnitude[d] = layers.ColumnNorms(m.params.Weight[d])
+			m.affineLayers[d] = layers.InitWeightNormLayer(m.params.Weight[d], m.params.Bias[d], m.params.Magnitude[d])
+		case ReparameterizationAlgorismSpectralNorm:
+			m.affineLayers[d] = layers.InitSpectralNormLayer(m.params.Weight[d], m.params.Bias[d])
+		}
+	}
+}
+
+func (m *MultiLayerNet) setTrainFlag(train bool) {
+	for d := 0; d < m.depth; d++ {
+		if l, ok := m.affineLayers[d].(layers.TrainFlagSetter); ok {
+			l.SetTrainFlag(train)
+		}
+		if l, ok := m.normalizationLayers[d].(layers.TrainFlagSetter); ok {
+			l.SetTrainFlag(train)
+		}
+		if l, ok := m.dropoutLayers[d].(layers.TrainFlagSetter); ok {
+			l.SetTrainFlag(train)
+		}
+	}
+}
+
+func (m *MultiLayerNet) setFreezeFlag(freeze bool) {
+	for d := 0; d < m.depth; d++ {
+		if l, ok := m.affineLayers[d].(layers.FreezeFlagSetter); ok {
+			l.SetFreezeFlag(freeze)
+		}
+		if l, ok := m.normalizationLayers[d].(layers.FreezeFlagSetter); ok {
+			l.SetFreezeFlag(freeze)
+		}
+		if l, ok := m.dropoutLayers[d].(layers.FreezeFlagSetter); ok {
+			l.SetFreezeFlag(freeze)
+		}
+	}
+}
+
+func (m *MultiLayerNet) predict(x *mat.Dense, train bool) *mat.Dense {
+	m.setTrainFlag(train)
+
+	inputs := make([]*mat.Dense, m.depth)
 	for i := 0; i < m.depth; i++ {
+		inputs[i] = x
 		x = m.affineLayers[i].Forward(x)
 		x = m.activationLayers[i].Forward(x)
 		x = m.normalizationLayers[i].Forward(x)
+		x = m.dropoutLayers[i].Forward(x)
+		if m.residualLayers[i] != nil {
+			x = m.residualLayers[i].Forward(x, inputs[m.skipFrom[i]])
+		}
 	}
 
 	return x
 }
 
-func (m *MultiLayerNet) Loss(x, t *mat.Dense) float64 {
-	y := m.Predict(x)
+func (m *MultiLayerNet) loss(x, t *mat.Dense, train bool) float64 {
+	y := m.predict(x, train)
 	return m.lastLayer.Forward(y, t)
 }
 
+// SetTrainFlag chooses whether Predict, Loss and Accuracy run the network as
+// in training or as at inference, which is the default. Gradient always runs
+// it as in training.
+func (m *MultiLayerNet) SetTrainFlag(train bool) {
+	m.train = train
+}
+
+func (m *MultiLayerNet) Predict(x *mat.Dense) *mat.Dense {
+	return m.predict(x, m.train)
+}
+
+func (m *MultiLayerNet) Loss(x, t *mat.Dense) float64 {
+	return m.loss(x, t, m.train)
+}
+
+// Accuracy returns the ratio of correctly classified samples. A network
+// trained for multi-label classification returns the Hamming accuracy with
+// a threshold of 0.5, and one trained for regression returns the
+// coefficient of determination R^2.
 func (m *MultiLayerNet) Accuracy(x, t *mat.Dense) float64 {
-	batchSize, _ := x.Dims()
+	switch m.output {
+	case OutputAlgorismSigmoidWithBCELoss:
+		return m.HammingAccuracy(x, t, 0.5)
+	case OutputAlgorismMeanSquaredError, OutputAlgorismMeanAbsoluteError, OutputAlgorismHuberLoss:
+		return m.RSquared(x, t)
+	}
 
 	y := m.Predict(x)
+	batchSize, _ := y.Dims()
 	sum := 0.0
 
 	for i := 0; i < batchSize; i++ {
@@ -123,49 +664,94 @@ func (m *MultiLayerNet) Accuracy(x, t *mat.Dense) float64 {
 	return sum / float64(batchSize)
 }
 
+// HammingAccuracy returns the ratio of labels, over every sample, whose
+// sigmoid output is on the same side of threshold as t.
+func (m *MultiLayerNet) HammingAccuracy(x, t *mat.Dense, threshold float64) float64 {
+	return hammingAccuracy(m.Predict(x), t, threshold)
+}
+
+// ExactMatchAccuracy returns the ratio of samples whose labels are all on
+// the same side of threshold as t.
+func (m *MultiLayerNet) ExactMatchAccuracy(x, t *mat.Dense, threshold float64) float64 {
+	return exactMatchAccuracy(m.Predict(x), t, threshold)
+}
+
+// RootMeanSquaredError returns the square root of the mean of the squared
+// errors over every output of every sample.
+func (m *MultiLayerNet) RootMeanSquaredError(x, t *mat.Dense) float64 {
+	return rootMeanSquaredError(m.Predict(x), t)
+}
+
+// RSquared returns the coefficient of determination R^2 of the predictions
+// for t.
+func (m *MultiLayerNet) RSquared(x, t *mat.Dense) float64 {
+	return rSquared(m.Predict(x), t)
+}
+
+// NumericalGradient evaluates the loss as in training, but with the layers
+// frozen, so that every evaluation sees the same dropout mask and neither
+// the running statistics of batch normalization nor the singular vectors of
+// spectral normalization change.
 func (m *MultiLayerNet) NumericalGradient(x, t *mat.Dense) *Params {
+	m.setFreezeFlag(true)
+	defer m.setFreezeFlag(false)
+
 	f := func(w *mat.Dense) float64 {
-		return m.Loss(x, t)
+		return m.loss(x, t, true)
 	}
 
-	grads := Params{
-		Weight: make([]*mat.Dense, m.depth),
-		Bias:   make([]*mat.Dense, m.depth),
-		Depth:  m.depth,
-	}
+	grads := InitParams(m.depth)
 
 	for d := 0; d < m.depth; d++ {
 		grads.Weight[d] = numericalGradient(f, m.params.Weight[d])
-		grads.Bias[d] = numericalGradient(f, m.params.Bias[d])
+		if m.params.Bias[d] != nil {
+			grads.Bias[d] = numericalGradient(f, m.params.Bias[d])
+		}
+		grads.Gamma[d] = numericalGradientOnSlice(f, m.params.Gamma[d])
+		grads.Beta[d] = numericalGradientOnSlice(f, m.params.Beta[d])
+		grads.Alpha[d] = numericalGradientOnSlice(f, m.params.Alpha[d])
+		grads.Magnitude[d] = numericalGradientOnSlice(f, m.params.Magnitude[d])
 	}
 
-	return &grads
+	return grads
 }
 
 func (m *MultiLayerNet) Gradient(x, t *mat.Dense) *Params {
-	m.Loss(x, t)
+	m.loss(x, t, true)
 
 	dout := m.lastLayer.Backward(1.0)
 
+	// dskip holds the gradients coming down the skip connections, to be
+	// added to the gradient of the input of the layer they start from.
+	dskip := make([]*mat.Dense, m.depth)
 	for i := m.depth - 1; i >= 0; i-- {
+		if m.residualLayers[i] != nil {
+			dout, dskip[m.skipFrom[i]] = m.residualLayers[i].Backward(dout)
+		}
+		dout = m.dropoutLayers[i].Backward(dout)
 		dout = m.normalizationLayers[i].Backward(dout)
 		dout = m.activationLayers[i].Backward(dout)
 		dout = m.affineLayers[i].Backward(dout)
+		if dskip[i] != nil {
+			dout.Add(dout, dskip[i])
+		}
 	}
 
-	weight := make([]*mat.Dense, m.depth)
-	bias := make([]*mat.Dense, m.depth)
+	grads := InitParams(m.depth)
 	for i := 0; i < m.depth; i++ {
-		weight[i] = m.affineLayers[i].GetDW()
-		bias[i] = m.affineLayers[i].GetDB()
-	}
-
-	grads := Params{
-		Weight: weight,
-		Bias:   bias,
+		grads.Weight[i] = m.affineLayers[i].GetDW()
+		grads.Bias[i] = m.affineLayers[i].GetDB()
+		grads.Gamma[i] = m.normalizationLayers[i].GetDGamma()
+		grads.Beta[i] = m.normalizationLayers[i].GetDBeta()
+		if l, ok := m.activationLayers[i].(layers.ParameterizedActivationLayer); ok {
+			grads.Alpha[i] = l.GetDAlpha()
+		}
+		if l, ok := m.affineLayers[i].(*layers.WeightNormLayer); ok {
+			grads.Magnitude[i] = l.GetDG()
+		}
 	}
 
-	return &grads
+	return grads
 }
 
 func (m *MultiLayerNet) GetParams() *Params {
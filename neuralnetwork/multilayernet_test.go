@@ -0,0 +1,74 @@
+package neuralnetwork
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestGroupNormNeedsConvLayers(t *testing.T) {
+	a := DefaultActivationConfig(ActivationAlgorismReLu)
+
+	for _, n := range []NormalizationAlgorism{NormalizationAlgorismGroupNorm, NormalizationAlgorismInstanceNorm} {
+		if _, err := InitMultiLayerNet([]int{4, 8, 2}, 0.1, a, n, 0, OutputAlgorismSoftmaxWithLoss); err == nil {
+			t.Errorf("normalization %d without convolution layers: no error", n)
+		}
+
+		convs := []ConvParam{{FilterNum: 4, FilterSize: 3, Stride: 1, Pad: 1, Groups: 2}}
+		if _, err := InitMultiLayerConvNet(1, 4, 4, convs, []int{2}, 0.1, a, n, 0, OutputAlgorismSoftmaxWithLoss); err != nil {
+			t.Errorf("normalization %d with convolution layers: %v", n, err)
+		}
+	}
+}
+
+func TestConvParamGroups(t *testing.T) {
+	a := DefaultActivationConfig(ActivationAlgorismReLu)
+
+	for _, c := range []struct {
+		groups int
+		valid  bool
+	}{{0, true}, {2, true}, {4, true}, {3, false}, {-1, false}} {
+		convs := []ConvParam{{FilterNum: 4, FilterSize: 3, Stride: 1, Pad: 1, Groups: c.groups}}
+		_, err := InitMultiLayerConvNet(1, 4, 4, convs, []int{2}, 0.1, a, NormalizationAlgorismGroupNorm, 0, OutputAlgorismSoftmaxWithLoss)
+		if (err == nil) != c.valid {
+			t.Errorf("%d groups of 4 channels: err = %v", c.groups, err)
+		}
+	}
+}
+
+func TestSetReparameterizationGradient(t *testing.T) {
+	x := mat.NewDense(3, 4, []float64{0.5, -1, 0.2, 0.8, -0.3, 0.4, 1, -0.6, 0.9, 0.1, -0.7, 0.3})
+	tt := mat.NewDense(3, 2, []float64{1, 0, 0, 1, 1, 0})
+	a := DefaultActivationConfig(ActivationAlgorismSigmoid)
+
+	for _, r := range []ReparameterizationAlgorism{ReparameterizationAlgorismWeightNorm, ReparameterizationAlgorismSpectralNorm} {
+		m, err := InitMultiLayerNet([]int{4, 5, 2}, 0.5, a, NormalizationAlgorismNo, 0, OutputAlgorismSoftmaxWithLoss)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nets := []NeuralNetwork{m, InitTwoLayerNet(4, 5, 2, 0.5)}
+
+		for _, n := range nets {
+			n.SetReparameterization(r)
+			// A first Gradient lets spectral normalization settle its
+			// singular vectors, which NumericalGradient keeps fixed.
+			n.Gradient(x, tt)
+			grads := n.Gradient(x, tt)
+			numerical := n.NumericalGradient(x, tt)
+
+			for d := 0; d < n.GetDepth(); d++ {
+				var diff mat.Dense
+				diff.Sub(grads.Weight[d], numerical.Weight[d])
+				if v := mat.Norm(&diff, math.Inf(1)); v > 1e-4 {
+					t.Errorf("reparameterization %d, %T: Weight of depth %d differs by %g", r, n, d, v)
+				}
+				for i, g := range grads.Magnitude[d] {
+					if v := math.Abs(g - numerical.Magnitude[d][i]); v > 1e-4 {
+						t.Errorf("reparameterization %d, %T: Magnitude of depth %d differs by %g", r, n, d, v)
+					}
+				}
+			}
+		}
+	}
+}
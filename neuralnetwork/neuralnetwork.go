@@ -4,6 +4,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/hasokon/twolayernet/layers"
 	"golang.org/x/exp/rand"
 	"gonum.org/v1/gonum/mat"
 )
@@ -12,10 +13,17 @@ type NeuralNetwork interface {
 	Predict(input *mat.Dense) *mat.Dense
 	Loss(x, t *mat.Dense) float64
 	Accuracy(x, t *mat.Dense) float64
+	HammingAccuracy(x, t *mat.Dense, threshold float64) float64
+	ExactMatchAccuracy(x, t *mat.Dense, threshold float64) float64
+	RootMeanSquaredError(x, t *mat.Dense) float64
+	RSquared(x, t *mat.Dense) float64
 	NumericalGradient(x, t *mat.Dense) *Params
 	Gradient(x, t *mat.Dense) *Params
 	GetParams() *Params
 	GetDepth() int
+	SetTrainFlag(train bool)
+	SetLastLayer(l layers.OutputLayer)
+	SetReparameterization(r ReparameterizationAlgorism)
 }
 
 func argmaxOnVec(v mat.Vector) int {
@@ -32,6 +40,96 @@ func argmaxOnVec(v mat.Vector) int {
 	return argmax
 }
 
+// labelMatches reports, for every element of y, whether the sigmoid of y
+// and t fall on the same side of threshold.
+func labelMatches(y, t *mat.Dense, threshold float64) [][]bool {
+	r, c := y.Dims()
+	matches := make([][]bool, r)
+	for i := 0; i < r; i++ {
+		matches[i] = make([]bool, c)
+		for j := 0; j < c; j++ {
+			p := 1 / (1 + math.Exp(-1.0*y.At(i, j)))
+			matches[i][j] = (p >= threshold) == (t.At(i, j) >= threshold)
+		}
+	}
+
+	return matches
+}
+
+func hammingAccuracy(y, t *mat.Dense, threshold float64) float64 {
+	r, c := y.Dims()
+	sum := 0.0
+	for _, row := range labelMatches(y, t, threshold) {
+		for _, match := range row {
+			if match {
+				sum = sum + 1.0
+			}
+		}
+	}
+
+	return sum / float64(r*c)
+}
+
+func exactMatchAccuracy(y, t *mat.Dense, threshold float64) float64 {
+	r, _ := y.Dims()
+	sum := 0.0
+	for _, row := range labelMatches(y, t, threshold) {
+		match := true
+		for _, m := range row {
+			match = match && m
+		}
+		if match {
+			sum = sum + 1.0
+		}
+	}
+
+	return sum / float64(r)
+}
+
+// rootMeanSquaredError returns the square root of the mean of the squared
+// differences between y and t over every element.
+func rootMeanSquaredError(y, t *mat.Dense) float64 {
+	r, c := y.Dims()
+	sum := 0.0
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			e := y.At(i, j) - t.At(i, j)
+			sum = sum + e*e
+		}
+	}
+
+	return math.Sqrt(sum / float64(r*c))
+}
+
+// rSquared returns 1 - SSres/SStot, computed over every element of t with
+// the mean of each column as the baseline.
+func rSquared(y, t *mat.Dense) float64 {
+	r, c := y.Dims()
+	mean := make([]float64, c)
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			mean[j] = mean[j] + t.At(i, j)/float64(r)
+		}
+	}
+
+	res := 0.0
+	tot := 0.0
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			e := y.At(i, j) - t.At(i, j)
+			d := t.At(i, j) - mean[j]
+			res = res + e*e
+			tot = tot + d*d
+		}
+	}
+
+	if tot == 0 {
+		return 0
+	}
+
+	return 1 - res/tot
+}
+
 func makeRandSliceFloat64(size int, param float64) []float64 {
 	slc := make([]float64, size)
 	rand.Seed(uint64(time.Now().UnixNano()))
@@ -82,3 +180,11 @@ func numericalGradient(f func(*mat.Dense) float64, x *mat.Dense) *mat.Dense {
 
 	return grad
 }
+
+func numericalGradientOnSlice(f func(*mat.Dense) float64, x []float64) []float64 {
+	if len(x) == 0 {
+		return nil
+	}
+
+	return numericalGradient(f, mat.NewDense(1, len(x), x)).RawRowView(0)
+}
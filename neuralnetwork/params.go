@@ -5,19 +5,23 @@ import (
 )
 
 type Params struct {
-	Weight []*mat.Dense
-	Bias   []*mat.Dense
-	Beta   [][]float64
-	Gamma  [][]float64
-	Depth  int
+	Weight    []*mat.Dense
+	Bias      []*mat.Dense
+	Beta      [][]float64
+	Gamma     [][]float64
+	Alpha     [][]float64
+	Magnitude [][]float64
+	Depth     int
 }
 
 func InitParams(depth int) *Params {
 	return &Params{
-		Weight: make([]*mat.Dense, depth),
-		Bias:   make([]*mat.Dense, depth),
-		Beta:   make([][]float64, depth),
-		Gamma:  make([][]float64, depth),
-		Depth:  depth,
+		Weight:    make([]*mat.Dense, depth),
+		Bias:      make([]*mat.Dense, depth),
+		Beta:      make([][]float64, depth),
+		Gamma:     make([][]float64, depth),
+		Alpha:     make([][]float64, depth),
+		Magnitude: make([][]float64, depth),
+		Depth:     depth,
 	}
 }
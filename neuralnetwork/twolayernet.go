@@ -19,7 +19,7 @@ type TwoLayerNet struct {
 func InitTwoLayerNet(inputsize, hiddensize, outputsize int, weightInitStd float64) NeuralNetwork {
 
 	t := TwoLayerNet{
-		params:     &Params{},
+		params:     InitParams(2),
 		inputSize:  inputsize,
 		hiddenSize: hiddensize,
 		outputSize: outputsize,
@@ -31,9 +31,6 @@ func InitTwoLayerNet(inputsize, hiddensize, outputsize int, weightInitStd float6
 	b1 := makeRandSliceFloat64(hiddensize, weightInitStd)
 	b2 := makeRandSliceFloat64(outputsize, weightInitStd)
 
-	t.params.Weight = make([]*mat.Dense, t.depth)
-	t.params.Bias = make([]*mat.Dense, t.depth)
-
 	t.params.Weight[0] = mat.NewDense(inputsize, hiddensize, w1)
 	t.params.Weight[1] = mat.NewDense(hiddensize, outputsize, w2)
 	t.params.Bias[0] = mat.NewDense(1, hiddensize, b1)
@@ -82,27 +79,50 @@ func (tl *TwoLayerNet) Accuracy(x, t *mat.Dense) float64 {
 	return sum / float64(batchSize)
 }
 
+func (tl *TwoLayerNet) HammingAccuracy(x, t *mat.Dense, threshold float64) float64 {
+	return hammingAccuracy(tl.Predict(x), t, threshold)
+}
+
+func (tl *TwoLayerNet) ExactMatchAccuracy(x, t *mat.Dense, threshold float64) float64 {
+	return exactMatchAccuracy(tl.Predict(x), t, threshold)
+}
+
+func (tl *TwoLayerNet) RootMeanSquaredError(x, t *mat.Dense) float64 {
+	return rootMeanSquaredError(tl.Predict(x), t)
+}
+
+func (tl *TwoLayerNet) RSquared(x, t *mat.Dense) float64 {
+	return rSquared(tl.Predict(x), t)
+}
+
 func (tl *TwoLayerNet) NumericalGradient(x, t *mat.Dense) *Params {
 	f := func(w *mat.Dense) float64 {
 		return tl.Loss(x, t)
 	}
 
 	grads := Params{
-		Weight: make([]*mat.Dense, tl.depth),
-		Bias:   make([]*mat.Dense, tl.depth),
-		Depth:  tl.depth,
+		Weight:    make([]*mat.Dense, tl.depth),
+		Bias:      make([]*mat.Dense, tl.depth),
+		Magnitude: make([][]float64, tl.depth),
+		Depth:     tl.depth,
 	}
 
 	grads.Weight[0] = numericalGradient(f, tl.params.Weight[0])
 	grads.Weight[1] = numericalGradient(f, tl.params.Weight[1])
 	grads.Bias[0] = numericalGradient(f, tl.params.Bias[0])
 	grads.Bias[1] = numericalGradient(f, tl.params.Bias[1])
+	grads.Magnitude[0] = numericalGradientOnSlice(f, tl.params.Magnitude[0])
+	grads.Magnitude[1] = numericalGradientOnSlice(f, tl.params.Magnitude[1])
 
 	return &grads
 }
 
+// Gradient evaluates the loss with the affine layers in training mode, so
+// that spectral normalization takes a step of power iteration.
 func (tl *TwoLayerNet) Gradient(x, t *mat.Dense) *Params {
+	tl.setAffineTrainFlag(true)
 	tl.Loss(x, t)
+	tl.setAffineTrainFlag(false)
 
 	dout := tl.lastLayer.Backward(1.0)
 
@@ -113,14 +133,20 @@ func (tl *TwoLayerNet) Gradient(x, t *mat.Dense) *Params {
 
 	weight := make([]*mat.Dense, tl.depth)
 	bias := make([]*mat.Dense, tl.depth)
+	magnitude := make([][]float64, tl.depth)
 	for i := 0; i < tl.depth; i++ {
 		weight[i] = tl.affineLayers[i].GetDW()
 		bias[i] = tl.affineLayers[i].GetDB()
+		if l, ok := tl.affineLayers[i].(*layers.WeightNormLayer); ok {
+			magnitude[i] = l.GetDG()
+		}
 	}
 
 	grads := Params{
-		Weight: weight,
-		Bias:   bias,
+		Weight:    weight,
+		Bias:      bias,
+		Magnitude: magnitude,
+		Depth:     tl.depth,
 	}
 
 	return &grads
@@ -133,3 +159,35 @@ func (tl *TwoLayerNet) GetParams() *Params {
 func (tl *TwoLayerNet) GetDepth() int {
 	return tl.depth
 }
+
+func (tl *TwoLayerNet) SetTrainFlag(train bool) {}
+
+func (tl *TwoLayerNet) SetLastLayer(l layers.OutputLayer) {
+	tl.lastLayer = l
+}
+
+func (tl *TwoLayerNet) setAffineTrainFlag(train bool) {
+	for _, l := range tl.affineLayers {
+		if l, ok := l.(layers.TrainFlagSetter); ok {
+			l.SetTrainFlag(train)
+		}
+	}
+}
+
+// SetReparameterization replaces both AffineLayers by WeightNormLayers or
+// SpectralNormLayers, as MultiLayerNet.SetReparameterization does.
+func (tl *TwoLayerNet) SetReparameterization(r ReparameterizationAlgorism) {
+	for d := 0; d < tl.depth; d++ {
+		if _, ok := tl.affineLayers[d].(*layers.AffineLayer); !ok {
+			continue
+		}
+
+		switch r {
+		case ReparameterizationAlgorismWeightNorm:
+			tl.params.Magnitude[d] = layers.ColumnNorms(tl.params.Weight[d])
+			tl.affineLayers[d] = layers.InitWeightNormLayer(tl.params.Weight[d], tl.params.Bias[d], tl.params.Magnitude[d])
+		case ReparameterizationAlgorismSpectralNorm:
+			tl.affineLayers[d] = layers.InitSpectralNormLayer(tl.params.Weight[d], tl.params.Bias[d])
+		}
+	}
+}
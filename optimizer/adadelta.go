@@ -0,0 +1,56 @@
+package optimizer
+
+import (
+	"math"
+
+	"github.com/hasokon/twolayernet/neuralnetwork"
+	"gonum.org/v1/gonum/mat"
+)
+
+// AdaDelta scales the gradient by the ratio of the roots of decaying
+// averages of the squared updates, dx2, and of the squared gradients, g2,
+// so that it needs no learning rate of its own. learningRate multiplies the
+// resulting update and is usually 1.0.
+type AdaDelta struct {
+	learningRate float64
+	rho          float64
+	epsilon      float64
+	weightDecay  float64
+	g2           *neuralnetwork.Params
+	dx2          *neuralnetwork.Params
+}
+
+func InitAdaDelta(depth int, learningrate, rho, epsilon, weightDecay float64) Optimizer {
+	return &AdaDelta{
+		learningRate: learningrate,
+		rho:          rho,
+		epsilon:      epsilon,
+		weightDecay:  weightDecay,
+		g2:           neuralnetwork.InitParams(depth),
+		dx2:          neuralnetwork.InitParams(depth),
+	}
+}
+
+func (a *AdaDelta) Update(params, grads *neuralnetwork.Params) {
+	for _, p := range parameters(params, grads, a.g2, a.dx2) {
+		if p.decay {
+			decay(p.param, a.learningRate*a.weightDecay)
+		}
+		a.update(p.param, p.grad, p.states[0], p.states[1])
+	}
+}
+
+func (a *AdaDelta) update(param, grad, g2, dx2 *mat.Dense) {
+	r, c := param.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			g := grad.At(i, j)
+			g2ij := a.rho*g2.At(i, j) + (1.0-a.rho)*g*g
+			g2.Set(i, j, g2ij)
+
+			dx := math.Sqrt(dx2.At(i, j)+a.epsilon) / math.Sqrt(g2ij+a.epsilon) * g
+			dx2.Set(i, j, a.rho*dx2.At(i, j)+(1.0-a.rho)*dx*dx)
+			param.Set(i, j, param.At(i, j)-a.learningRate*dx)
+		}
+	}
+}
@@ -9,49 +9,37 @@ import (
 
 type AdaGrad struct {
 	learningRate float64
+	weightDecay  float64
 	h            *neuralnetwork.Params
 }
 
-func InitAdaGrad(depth int, learningrate float64) Optimizer {
+func InitAdaGrad(depth int, learningrate, weightDecay float64) Optimizer {
 	return &AdaGrad{
 		learningRate: learningrate,
+		weightDecay:  weightDecay,
 		h:            neuralnetwork.InitParams(depth),
 	}
 }
 
 func (a *AdaGrad) Update(params, grads *neuralnetwork.Params) {
-
-	delta := math.Pow10(-7)
-
-	for d := 0; d < a.h.Depth; d++ {
-		// Weight
-		r, c := params.Weight[d].Dims()
-		if a.h.Weight[d] == nil {
-			a.h.Weight[d] = mat.NewDense(r, c, nil)
-		}
-
-		for i := 0; i < r; i++ {
-			for j := 0; j < c; j++ {
-				grad := grads.Weight[d].At(i, j)
-				h := a.h.Weight[d].At(i, j) + grad*grad
-				a.h.Weight[d].Set(i, j, h)
-				params.Weight[d].Set(i, j, params.Weight[d].At(i, j)-a.learningRate*grad/(math.Sqrt(h)+delta))
-			}
+	for _, p := range parameters(params, grads, a.h) {
+		if p.decay {
+			decay(p.param, a.learningRate*a.weightDecay)
 		}
+		a.update(p.param, p.grad, p.states[0])
+	}
+}
 
-		// Bias
-		r, c = params.Bias[d].Dims()
-		if a.h.Bias[d] == nil {
-			a.h.Bias[d] = mat.NewDense(r, c, nil)
-		}
+func (a *AdaGrad) update(param, grad, h *mat.Dense) {
+	delta := math.Pow10(-7)
 
-		for i := 0; i < r; i++ {
-			for j := 0; j < c; j++ {
-				grad := grads.Bias[d].At(i, j)
-				h := a.h.Bias[d].At(i, j) + grad*grad
-				a.h.Bias[d].Set(i, j, h)
-				params.Bias[d].Set(i, j, params.Bias[d].At(i, j)-a.learningRate*grad/(math.Sqrt(h)+delta))
-			}
+	r, c := param.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			g := grad.At(i, j)
+			hij := h.At(i, j) + g*g
+			h.Set(i, j, hij)
+			param.Set(i, j, param.At(i, j)-a.learningRate*g/(math.Sqrt(hij)+delta))
 		}
 	}
 }
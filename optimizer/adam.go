@@ -0,0 +1,82 @@
+package optimizer
+
+import (
+	"math"
+
+	"github.com/hasokon/twolayernet/neuralnetwork"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Adam keeps decaying averages m and v of the gradients and of their
+// squares, corrected for their bias towards 0 in the first iterations. The
+// AMSGrad variant divides by the largest v seen so far instead of the
+// current one, so that the step size never grows.
+type Adam struct {
+	learningRate float64
+	beta1        float64
+	beta2        float64
+	epsilon      float64
+	weightDecay  float64
+	amsgrad      bool
+	iter         int
+	m            *neuralnetwork.Params
+	v            *neuralnetwork.Params
+	vmax         *neuralnetwork.Params
+}
+
+func InitAdam(depth int, learningrate, beta1, beta2, epsilon, weightDecay float64, amsgrad bool) Optimizer {
+	return &Adam{
+		learningRate: learningrate,
+		beta1:        beta1,
+		beta2:        beta2,
+		epsilon:      epsilon,
+		weightDecay:  weightDecay,
+		amsgrad:      amsgrad,
+		m:            neuralnetwork.InitParams(depth),
+		v:            neuralnetwork.InitParams(depth),
+		vmax:         neuralnetwork.InitParams(depth),
+	}
+}
+
+func (a *Adam) Update(params, grads *neuralnetwork.Params) {
+	a.iter++
+
+	states := []*neuralnetwork.Params{a.m, a.v}
+	if a.amsgrad {
+		states = append(states, a.vmax)
+	}
+
+	for _, p := range parameters(params, grads, states...) {
+		if p.decay {
+			decay(p.param, a.learningRate*a.weightDecay)
+		}
+
+		// Without AMSGrad the largest v is not kept, and v itself is used.
+		vmax := p.states[len(p.states)-1]
+		a.update(p.param, p.grad, p.states[0], p.states[1], vmax)
+	}
+}
+
+func (a *Adam) update(param, grad, m, v, vmax *mat.Dense) {
+	correction1 := 1.0 - math.Pow(a.beta1, float64(a.iter))
+	correction2 := 1.0 - math.Pow(a.beta2, float64(a.iter))
+
+	r, c := param.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			g := grad.At(i, j)
+			mij := a.beta1*m.At(i, j) + (1.0-a.beta1)*g
+			vij := a.beta2*v.At(i, j) + (1.0-a.beta2)*g*g
+			m.Set(i, j, mij)
+			v.Set(i, j, vij)
+			if a.amsgrad {
+				vij = math.Max(vmax.At(i, j), vij)
+				vmax.Set(i, j, vij)
+			}
+
+			mhat := mij / correction1
+			vhat := vij / correction2
+			param.Set(i, j, param.At(i, j)-a.learningRate*mhat/(math.Sqrt(vhat)+a.epsilon))
+		}
+	}
+}
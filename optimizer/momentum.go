@@ -8,43 +8,35 @@ import (
 type Momentum struct {
 	learningRate float64
 	momentum     float64
+	weightDecay  float64
 	v            *neuralnetwork.Params
 }
 
-func InitMomentum(depth int, learningrate, momentum float64) Optimizer {
+func InitMomentum(depth int, learningrate, momentum, weightDecay float64) Optimizer {
 	return &Momentum{
 		learningRate: learningrate,
 		momentum:     momentum,
+		weightDecay:  weightDecay,
 		v:            neuralnetwork.InitParams(depth),
 	}
 }
 
 func (m *Momentum) Update(params, grads *neuralnetwork.Params) {
-	for d := 0; d < m.v.Depth; d++ {
-		// Weight
-		r, c := params.Weight[d].Dims()
-		if m.v.Weight[d] == nil {
-			m.v.Weight[d] = mat.NewDense(r, c, nil)
-		}
-		for i := 0; i < r; i++ {
-			for j := 0; j < c; j++ {
-				p := m.momentum*m.v.Weight[d].At(i, j) - grads.Weight[d].At(i, j)*m.learningRate
-				m.v.Weight[d].Set(i, j, p)
-				params.Weight[d].Set(i, j, params.Weight[d].At(i, j)+p)
-			}
+	for _, p := range parameters(params, grads, m.v) {
+		if p.decay {
+			decay(p.param, m.learningRate*m.weightDecay)
 		}
+		m.update(p.param, p.grad, p.states[0])
+	}
+}
 
-		// Bias
-		r, c = params.Bias[d].Dims()
-		if m.v.Bias[d] == nil {
-			m.v.Bias[d] = mat.NewDense(r, c, nil)
-		}
-		for i := 0; i < r; i++ {
-			for j := 0; j < c; j++ {
-				p := m.momentum*m.v.Bias[d].At(i, j) - grads.Bias[d].At(i, j)*m.learningRate
-				m.v.Bias[d].Set(i, j, p)
-				params.Bias[d].Set(i, j, params.Bias[d].At(i, j)+p)
-			}
+func (m *Momentum) update(param, grad, v *mat.Dense) {
+	r, c := param.Dims()
+	for i := 0; i < r; i++ {
+		for j := 0; j < c; j++ {
+			p := m.momentum*v.At(i, j) - grad.At(i, j)*m.learningRate
+			v.Set(i, j, p)
+			param.Set(i, j, param.At(i, j)+p)
 		}
 	}
 }
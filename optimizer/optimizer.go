@@ -1,11 +1,20 @@
 package optimizer
 
-import "github.com/hasokon/twolayernet/neuralnetwork"
+import (
+	"math"
+
+	"github.com/hasokon/twolayernet/neuralnetwork"
+	"gonum.org/v1/gonum/mat"
+)
 
 const (
 	AlgorismSGD = iota
 	AlgorismMomentum
 	AlgorismAdaGrad
+	AlgorismAdam
+	AlgorismAMSGrad
+	AlgorismRMSProp
+	AlgorismAdaDelta
 )
 
 type Algorism int
@@ -14,15 +23,156 @@ type Optimizer interface {
 	Update(params, grads *neuralnetwork.Params)
 }
 
+// Hyperparameters holds the settings of the optimizers other than the
+// learning rate. Each optimizer reads only those it uses.
+//
+// WeightDecay is the decoupled weight decay: every update first shrinks the
+// weights by learningRate*WeightDecay of themselves, independently of the
+// gradient, which makes SGD into SGDW and Adam into AdamW. Bias, Gamma,
+// Beta, Alpha and Magnitude are never decayed.
+//
+// Rho is the decay rate of the averages of RMSProp and AdaDelta. RMSProp
+// uses RMSPropMomentum rather than Momentum, and is centered when Centered
+// is true. AdaDelta uses AdaDeltaEpsilon rather than Epsilon, which also
+// sets the size of its first steps.
+type Hyperparameters struct {
+	Momentum        float64
+	Beta1           float64
+	Beta2           float64
+	Epsilon         float64
+	WeightDecay     float64
+	Rho             float64
+	RMSPropMomentum float64
+	Centered        bool
+	AdaDeltaEpsilon float64
+}
+
+func DefaultHyperparameters() Hyperparameters {
+	return Hyperparameters{
+		Momentum:        0.9,
+		Beta1:           0.9,
+		Beta2:           0.999,
+		Epsilon:         math.Pow10(-8),
+		WeightDecay:     0.0,
+		Rho:             0.9,
+		RMSPropMomentum: 0.0,
+		Centered:        false,
+		AdaDeltaEpsilon: math.Pow10(-6),
+	}
+}
+
 func InitOptimizer(depth int, learningRate float64, a Algorism) Optimizer {
+	return InitOptimizerWithHyperparameters(depth, learningRate, a, DefaultHyperparameters())
+}
+
+// InitOptimizerWithHyperparameters is InitOptimizer with settings other than
+// the defaults. AdaDelta scales its own steps, so the learningRate usually
+// given to it is 1.0.
+func InitOptimizerWithHyperparameters(depth int, learningRate float64, a Algorism, h Hyperparameters) Optimizer {
 	switch a {
 	case AlgorismSGD:
-		return InitSGD(learningRate)
+		return InitSGD(learningRate, h.WeightDecay)
 	case AlgorismMomentum:
-		return InitMomentum(depth, learningRate, 0.9)
+		return InitMomentum(depth, learningRate, h.Momentum, h.WeightDecay)
 	case AlgorismAdaGrad:
-		return InitAdaGrad(depth, learningRate)
+		return InitAdaGrad(depth, learningRate, h.WeightDecay)
+	case AlgorismAdam:
+		return InitAdam(depth, learningRate, h.Beta1, h.Beta2, h.Epsilon, h.WeightDecay, false)
+	case AlgorismAMSGrad:
+		return InitAdam(depth, learningRate, h.Beta1, h.Beta2, h.Epsilon, h.WeightDecay, true)
+	case AlgorismRMSProp:
+		return InitRMSProp(depth, learningRate, h.Rho, h.RMSPropMomentum, h.Epsilon, h.WeightDecay, h.Centered)
+	case AlgorismAdaDelta:
+		return InitAdaDelta(depth, learningRate, h.Rho, h.AdaDeltaEpsilon, h.WeightDecay)
+	}
+
+	return InitSGD(learningRate, h.WeightDecay)
+}
+
+func zerosLike(m *mat.Dense) *mat.Dense {
+	r, c := m.Dims()
+	return mat.NewDense(r, c, nil)
+}
+
+// sliceToDense wraps s in a 1 x len(s) matrix sharing its memory, so that
+// updates made through the matrix are visible in s.
+func sliceToDense(s []float64) *mat.Dense {
+	return mat.NewDense(1, len(s), s)
+}
+
+// decay shrinks param by the fraction rate of itself.
+func decay(param *mat.Dense, rate float64) {
+	if rate != 0 {
+		param.Scale(1.0-rate, param)
+	}
+}
+
+func hasSliceGrad(grads [][]float64, d int) bool {
+	return d < len(grads) && len(grads[d]) > 0
+}
+
+// parameter is one parameter matrix with its gradient and the matching
+// matrices of the states of an optimizer. Only Weight is decayed.
+type parameter struct {
+	param  *mat.Dense
+	grad   *mat.Dense
+	states []*mat.Dense
+	decay  bool
+}
+
+// parameters returns every parameter of params that has a gradient in
+// grads, depth by depth, allocating its matrices in states as zeros the
+// first time. Gamma, Beta, Alpha and Magnitude are wrapped by sliceToDense.
+func parameters(params, grads *neuralnetwork.Params, states ...*neuralnetwork.Params) []parameter {
+	denseFields := func(p *neuralnetwork.Params) [][]*mat.Dense {
+		return [][]*mat.Dense{p.Weight, p.Bias}
+	}
+	sliceFields := func(p *neuralnetwork.Params) [][][]float64 {
+		return [][][]float64{p.Gamma, p.Beta, p.Alpha, p.Magnitude}
+	}
+
+	ps := []parameter{}
+	for d := 0; d < params.Depth; d++ {
+		for f, grad := range denseFields(grads) {
+			if grad[d] == nil {
+				continue
+			}
+
+			p := parameter{
+				param: denseFields(params)[f][d],
+				grad:  grad[d],
+				decay: f == 0,
+			}
+			for _, state := range states {
+				field := denseFields(state)[f]
+				if field[d] == nil {
+					field[d] = zerosLike(p.param)
+				}
+				p.states = append(p.states, field[d])
+			}
+			ps = append(ps, p)
+		}
+
+		for f, grad := range sliceFields(grads) {
+			if !hasSliceGrad(grad, d) {
+				continue
+			}
+
+			param := sliceFields(params)[f][d]
+			p := parameter{
+				param: sliceToDense(param),
+				grad:  sliceToDense(grad[d]),
+			}
+			for _, state := range states {
+				field := sliceFields(state)[f]
+				if field[d] == nil {
+					field[d] = make([]float64, len(param))
+				}
+				p.states = append(p.states, sliceToDense(field[d]))
+			}
+			ps = append(ps, p)
+		}
 	}
 
-	return InitSGD(learningRate)
+	return ps
 }
@@ -0,0 +1,199 @@
+package optimizer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hasokon/twolayernet/neuralnetwork"
+	"gonum.org/v1/gonum/mat"
+)
+
+// quadraticParams returns parameters of every kind the optimizers update.
+func quadraticParams() *neuralnetwork.Params {
+	params := neuralnetwork.InitParams(1)
+	params.Weight[0] = mat.NewDense(2, 2, []float64{1, -2, 0.5, 3})
+	params.Bias[0] = mat.NewDense(1, 2, []float64{-1, 2})
+	params.Gamma[0] = []float64{1.5, -0.5}
+	params.Beta[0] = []float64{0.5, -1}
+	params.Alpha[0] = []float64{0.25, -0.75}
+	params.Magnitude[0] = []float64{2, 1}
+	return params
+}
+
+// quadraticGrads returns the gradients of sum(p^2)/2 over every parameter,
+// which are the parameters themselves.
+func quadraticGrads(params *neuralnetwork.Params) *neuralnetwork.Params {
+	grads := neuralnetwork.InitParams(params.Depth)
+	for d := 0; d < params.Depth; d++ {
+		grads.Weight[d] = mat.DenseCopyOf(params.Weight[d])
+		grads.Bias[d] = mat.DenseCopyOf(params.Bias[d])
+		grads.Gamma[d] = append([]float64(nil), params.Gamma[d]...)
+		grads.Beta[d] = append([]float64(nil), params.Beta[d]...)
+		grads.Alpha[d] = append([]float64(nil), params.Alpha[d]...)
+		grads.Magnitude[d] = append([]float64(nil), params.Magnitude[d]...)
+	}
+	return grads
+}
+
+// maxAbs returns the largest absolute value over every parameter.
+func maxAbs(params *neuralnetwork.Params) float64 {
+	max := 0.0
+	for d := 0; d < params.Depth; d++ {
+		for _, m := range []*mat.Dense{params.Weight[d], params.Bias[d], sliceToDense(params.Gamma[d]), sliceToDense(params.Beta[d]), sliceToDense(params.Alpha[d]), sliceToDense(params.Magnitude[d])} {
+			max = math.Max(max, mat.Norm(m, math.Inf(1)))
+		}
+	}
+	return max
+}
+
+func TestOptimizersMinimizeQuadratic(t *testing.T) {
+	cases := []struct {
+		name string
+		o    Optimizer
+	}{
+		{"SGD", InitSGD(0.1, 0)},
+		{"Momentum", InitMomentum(1, 0.1, 0.9, 0)},
+		{"AdaGrad", InitAdaGrad(1, 0.5, 0)},
+		{"Adam", InitAdam(1, 0.05, 0.9, 0.999, 1e-8, 0, false)},
+		{"AMSGrad", InitAdam(1, 0.05, 0.9, 0.999, 1e-8, 0, true)},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params := quadraticParams()
+			for i := 0; i < 500; i++ {
+				c.o.Update(params, quadraticGrads(params))
+			}
+			if max := maxAbs(params); max > 1e-3 {
+				t.Errorf("largest parameter after 500 steps = %g, want about 0", max)
+			}
+		})
+	}
+}
+
+func TestSGDUpdate(t *testing.T) {
+	params := quadraticParams()
+	InitSGD(0.1, 0).Update(params, quadraticGrads(params))
+
+	if got, want := params.Weight[0].At(0, 1), -2*0.9; math.Abs(got-want) > 1e-12 {
+		t.Errorf("weight = %g, want %g", got, want)
+	}
+	if got, want := params.Gamma[0][0], 1.5*0.9; math.Abs(got-want) > 1e-12 {
+		t.Errorf("gamma = %g, want %g", got, want)
+	}
+}
+
+func TestWeightDecayShrinksOnlyWeights(t *testing.T) {
+	cases := []struct {
+		name      string
+		optimizer Optimizer
+	}{
+		{"SGD", InitSGD(0.1, 0.5)},
+		{"Momentum", InitMomentum(1, 0.1, 0.9, 0.5)},
+		{"AdaGrad", InitAdaGrad(1, 0.1, 0.5)},
+		{"Adam", InitAdam(1, 0.1, 0.9, 0.999, 1e-8, 0.5, false)},
+	}
+
+	for _, c := range cases {
+		params := quadraticParams()
+		grads := quadraticGrads(params)
+		grads.Weight[0].Zero()
+		grads.Bias[0].Zero()
+		for _, g := range [][]float64{grads.Gamma[0], grads.Beta[0], grads.Alpha[0], grads.Magnitude[0]} {
+			for i := range g {
+				g[i] = 0
+			}
+		}
+
+		c.optimizer.Update(params, grads)
+
+		want := quadraticParams()
+		want.Weight[0].Scale(1-0.1*0.5, want.Weight[0])
+		if !mat.EqualApprox(params.Weight[0], want.Weight[0], 1e-12) {
+			t.Errorf("%s: weight = %v, want %v", c.name, mat.Formatted(params.Weight[0]), mat.Formatted(want.Weight[0]))
+		}
+		if !mat.Equal(params.Bias[0], want.Bias[0]) || !mat.Equal(sliceToDense(params.Gamma[0]), sliceToDense(want.Gamma[0])) {
+			t.Errorf("%s: bias and gamma must not be decayed", c.name)
+		}
+	}
+}
+
+func TestAdamFirstStep(t *testing.T) {
+	params := quadraticParams()
+	InitAdam(1, 0.1, 0.9, 0.999, 1e-8, 0, false).Update(params, quadraticGrads(params))
+
+	// With the bias correction the first step is the learning rate in the
+	// direction opposite to the sign of the gradient.
+	if got, want := params.Weight[0].At(0, 1), -2+0.1; math.Abs(got-want) > 1e-6 {
+		t.Errorf("weight = %g, want %g", got, want)
+	}
+	if got, want := params.Beta[0][1], -1+0.1; math.Abs(got-want) > 1e-6 {
+		t.Errorf("beta = %g, want %g", got, want)
+	}
+}
+
+func TestAMSGradKeepsLargestSecondMoment(t *testing.T) {
+	step := func(amsgrad bool) float64 {
+		o := InitAdam(1, 0.1, 0.9, 0.999, 1e-8, 0, amsgrad)
+		params := neuralnetwork.InitParams(1)
+		params.Weight[0] = mat.NewDense(1, 1, nil)
+		grads := neuralnetwork.InitParams(1)
+
+		for _, g := range []float64{10, 10, 0.01, 0.01, 0.01} {
+			grads.Weight[0] = mat.NewDense(1, 1, []float64{g})
+			o.Update(params, grads)
+		}
+		before := params.Weight[0].At(0, 0)
+		o.Update(params, grads)
+		return math.Abs(params.Weight[0].At(0, 0) - before)
+	}
+
+	if adam, amsgrad := step(false), step(true); amsgrad > adam {
+		t.Errorf("AMSGrad step %g is larger than the Adam one %g after the gradients shrank", amsgrad, adam)
+	}
+}
+
+func TestRMSPropSteps(t *testing.T) {
+	// Two steps on a constant gradient of 1 with rho 0.9, so that v is 0.1
+	// and then 0.19, and the centered mean of the gradients 0.1 and then 0.19.
+	lr := 0.01
+	cases := []struct {
+		name      string
+		optimizer Optimizer
+		want      float64
+	}{
+		{"plain", InitRMSProp(1, lr, 0.9, 0, 0, 0, false), lr/math.Sqrt(0.1) + lr/math.Sqrt(0.19)},
+		{"centered", InitRMSProp(1, lr, 0.9, 0, 0, 0, true), lr/math.Sqrt(0.1-0.01) + lr/math.Sqrt(0.19-0.19*0.19)},
+		{"momentum", InitRMSProp(1, lr, 0.9, 0.5, 0, 0, false), 1.5*lr/math.Sqrt(0.1) + lr/math.Sqrt(0.19)},
+	}
+
+	for _, c := range cases {
+		params := neuralnetwork.InitParams(1)
+		params.Weight[0] = mat.NewDense(1, 1, nil)
+		grads := neuralnetwork.InitParams(1)
+		grads.Weight[0] = mat.NewDense(1, 1, []float64{1})
+
+		c.optimizer.Update(params, grads)
+		c.optimizer.Update(params, grads)
+
+		if got := -params.Weight[0].At(0, 0); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("%s: moved by %g, want %g", c.name, got, c.want)
+		}
+	}
+}
+
+func TestAdaDeltaFirstStep(t *testing.T) {
+	rho, epsilon := 0.9, 1e-6
+	for _, lr := range []float64{1.0, 2.0} {
+		params := quadraticParams()
+		InitAdaDelta(1, lr, rho, epsilon, 0).Update(params, quadraticGrads(params))
+
+		// Both averages start at 0, so the first step is the gradient
+		// scaled by sqrt(epsilon)/sqrt((1-rho)*g^2+epsilon), times lr.
+		g := 3.0
+		want := g - lr*math.Sqrt(epsilon)/math.Sqrt((1-rho)*g*g+epsilon)*g
+		if got := params.Weight[0].At(1, 1); math.Abs(got-want) > 1e-12 {
+			t.Errorf("learning rate %g: weight = %g, want %g", lr, got, want)
+		}
+	}
+}
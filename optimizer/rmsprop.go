@@ -0,0 +1,68 @@
+package optimizer
+
+import (
+	"math"
+
+	"github.com/hasokon/twolayernet/neuralnetwork"
+	"gonum.org/v1/gonum/mat"
+)
+
+// RMSProp divides the gradient by the root of a decaying average v of its
+// square, so that, unlike AdaGrad, the step size does not shrink forever.
+// The centered variant subtracts the square of the decaying average of the
+// gradient from v, which estimates its variance instead. With a momentum
+// greater than 0 the steps are accumulated in buf like Momentum.
+type RMSProp struct {
+	learningRate float64
+	rho          float64
+	momentum     float64
+	epsilon      float64
+	weightDecay  float64
+	centered     bool
+	v            *neuralnetwork.Params
+	mg           *neuralnetwork.Params
+	buf          *neuralnetwork.Params
+}
+
+func InitRMSProp(depth int, learningrate, rho, momentum, epsilon, weightDecay float64, centered bool) Optimizer {
+	return &RMSProp{
+		learningRate: learningrate,
+		rho:          rho,
+		momentum:     momentum,
+		epsilon:      epsilon,
+		weightDecay:  weightDecay,
+		centered:     centered,
+		v:            neuralnetwork.InitParams(depth),
+		mg:           neuralnetwork.InitParams(depth),
+		buf:          neuralnetwork.InitParams(depth),
+	}
+}
+
+func (r *RMSProp) Update(params, grads *neuralnetwork.Params) {
+	for _, p := range parameters(params, grads, r.v, r.mg, r.buf) {
+		if p.decay {
+			decay(p.param, r.learningRate*r.weightDecay)
+		}
+		r.update(p.param, p.grad, p.states[0], p.states[1], p.states[2])
+	}
+}
+
+func (r *RMSProp) update(param, grad, v, mg, buf *mat.Dense) {
+	rows, cols := param.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			g := grad.At(i, j)
+			vij := r.rho*v.At(i, j) + (1.0-r.rho)*g*g
+			v.Set(i, j, vij)
+			if r.centered {
+				mgij := r.rho*mg.At(i, j) + (1.0-r.rho)*g
+				mg.Set(i, j, mgij)
+				vij = math.Max(vij-mgij*mgij, 0.0)
+			}
+
+			step := r.momentum*buf.At(i, j) + r.learningRate*g/(math.Sqrt(vij)+r.epsilon)
+			buf.Set(i, j, step)
+			param.Set(i, j, param.At(i, j)-step)
+		}
+	}
+}